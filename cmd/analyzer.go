@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// analyzerSettingsFile is the settings file PSScriptAnalyzer editors pick up by convention
+const analyzerSettingsFile = "PSScriptAnalyzerSettings.psd1"
+
+// AnalyzerDiagnostic is one diagnostic record from Invoke-ScriptAnalyzer
+type AnalyzerDiagnostic struct {
+	RuleName string `json:"ruleName"`
+	Severity string `json:"severity"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+}
+
+// builtinAnalyzerEquivalents maps built-in rules to the PSScriptAnalyzer rule reporting the
+// same issue, so the built-in finding is dropped when PSScriptAnalyzer already reported it
+var builtinAnalyzerEquivalents = map[string]string{
+	"BP002": "PSAvoidUsingWriteHost",
+	"BP004": "PSAvoidUsingCmdletAliases",
+}
+
+// scriptAnalyzerVersion returns the installed PSScriptAnalyzer version, or "" when it is not installed
+func scriptAnalyzerVersion() (string, error) {
+	output, err := runPowerShellScript("analyzer-version.ps1", nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// resolveAnalyzerSettings turns the --settings flag into what Invoke-ScriptAnalyzer -Settings
+// expects: an absolute settings file path or a preset name such as PSGallery. Without the flag,
+// PSScriptAnalyzerSettings.psd1 in the current directory is used when present.
+func resolveAnalyzerSettings(flag string) (string, error) {
+	if flag == "" {
+		if _, err := os.Stat(analyzerSettingsFile); err == nil {
+			return filepath.Abs(analyzerSettingsFile)
+		}
+		return "", nil
+	}
+	if _, err := os.Stat(flag); err == nil {
+		return filepath.Abs(flag)
+	}
+	if strings.HasSuffix(strings.ToLower(flag), ".psd1") || strings.ContainsAny(flag, `/\`) {
+		return "", os.ErrNotExist
+	}
+	return flag, nil
+}
+
+// runScriptAnalyzer runs Invoke-ScriptAnalyzer on filename and returns its diagnostics as findings
+func runScriptAnalyzer(filename, settings string) ([]Finding, error) {
+	path, err := filepath.Abs(sourceFile(filename))
+	if err != nil {
+		return nil, err
+	}
+	params := scriptParams{"Path": path}
+	if settings != "" {
+		params["Settings"] = settings
+	}
+	output, err := runPowerShellScript("analyze.ps1", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Available   bool                 `json:"available"`
+		Diagnostics []AnalyzerDiagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, err
+	}
+
+	findings := make([]Finding, 0, len(result.Diagnostics))
+	for _, diagnostic := range result.Diagnostics {
+		findings = append(findings, Finding{
+			RuleID:   diagnostic.RuleName,
+			Severity: analyzerSeverity(diagnostic.Severity),
+			Category: categoryBestPractices,
+			File:     filename,
+			Line:     diagnostic.Line,
+			Column:   diagnostic.Column,
+			Message:  diagnostic.Message,
+			Source:   sourceAnalyzer,
+		})
+	}
+	return findings, nil
+}
+
+// analyzerSeverity maps a PSScriptAnalyzer severity to a finding severity
+func analyzerSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "error", "parseerror":
+		return severityError
+	case "warning":
+		return severityWarning
+	default:
+		return severityInfo
+	}
+}
+
+// mergeFindings combines built-in and PSScriptAnalyzer findings, dropping built-in findings
+// that PSScriptAnalyzer also reported
+func mergeFindings(builtin, analyzer []Finding) []Finding {
+	reported := map[string]bool{}
+	for _, finding := range analyzer {
+		reported[finding.RuleID] = true
+	}
+
+	merged := append([]Finding{}, analyzer...)
+	for _, finding := range builtin {
+		if equivalent, ok := builtinAnalyzerEquivalents[finding.RuleID]; ok && reported[equivalent] {
+			continue
+		}
+		merged = append(merged, finding)
+	}
+	sortFindings(merged)
+	return merged
+}
@@ -10,55 +10,56 @@ var backCmd = &cobra.Command{
 	Use:   "back",
 	Short: "Navigate back to the previous PowerShell course",
 	Long:  `Go back to the previous PowerShell GitHub Skills course in sequence`,
-	Run: func(cmd *cobra.Command, args []string) {
-		navigateBack()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return navigateBack()
 	},
 }
 
-func navigateBack() {
-	fmt.Println("⏮️  PowerShell GitHub Skills - Previous Course")
+func navigateBack() error {
+	fmt.Println(T("back.title"))
 	fmt.Println("=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=")
 
 	// Check if we're in a git repository
 	if !isGitRepo() {
-		fmt.Println("❌ Not in a git repository. Please run from your PowerShell Skills course directory.")
-		return
+		fmt.Println(T("common.not_git_repo"))
+		return reportedError(exitNotInCourse, "not in a git repository")
 	}
 
 	// Detect current course and find previous
 	currentCourse := DetectCurrentCourseInfo()
 	if currentCourse == nil {
-		fmt.Println("❌ Could not detect current course. Please ensure you're in a PowerShell Skills course directory.")
-		return
+		fmt.Println(T("common.no_current_course"))
+		return reportedError(exitNotInCourse, "no current course")
 	}
 
 	previousCourse := GetPreviousCourseInfo(currentCourse)
 	if previousCourse == nil {
-		fmt.Println("🎯 You're already at the first course!")
-		fmt.Println("💡 This is where your PowerShell journey begins. Move forward with 'gh pwsh-skills next' when ready!")
-		return
+		fmt.Println(T("back.first_course"))
+		fmt.Println(T("back.journey_begins"))
+		return nil
 	}
 
 	// Navigate to previous course
-	fmt.Printf("📍 Current: %s\n", currentCourse.Name)
-	fmt.Printf("⏮️  Previous: %s\n\n", previousCourse.Name)
+	fmt.Println(T("navigate.current", currentCourse.Name))
+	fmt.Printf("%s\n\n", T("back.previous_course", previousCourse.Name))
 
 	if err := NavigateToCourseDirectory(previousCourse); err != nil {
-		fmt.Printf("❌ Error changing to directory '%s': %v\n", previousCourse.Directory, err)
-		fmt.Printf("💡 You may need to manually switch to the directory: %s\n", previousCourse.Directory)
-		return
+		fmt.Println(T("navigate.chdir_error", previousCourse.Directory, err))
+		fmt.Println(T("navigate.manual_switch", previousCourse.Directory))
+		return reported(err)
 	}
 
-	fmt.Printf("✅ Successfully navigated back to: %s\n", previousCourse.Name)
-	fmt.Printf("📂 Directory: %s\n\n", previousCourse.Directory)
+	fmt.Println(T("back.success", previousCourse.Name))
+	fmt.Printf("%s\n\n", T("navigate.directory", previousCourse.Directory))
 	
 	// Show what to do next
-	fmt.Println("🔄 Back to previous course!")
-	fmt.Println("You can:")
-	fmt.Println("1. Review the course content")
-	fmt.Println("2. Re-read the README.md")
-	fmt.Println("3. Use 'gh pwsh-skills status' to check progress")
-	fmt.Println("4. Use 'gh pwsh-skills next' to move forward again")
+	fmt.Println(T("back.heading"))
+	fmt.Println(T("back.you_can"))
+	fmt.Println("1. " + T("back.step.review"))
+	fmt.Println("2. " + T("back.step.readme"))
+	fmt.Println("3. " + T("back.step.status"))
+	fmt.Println("4. " + T("back.step.next"))
+	return nil
 }
 
 func init() {
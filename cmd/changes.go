@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// changedBaseDefault is the base of --changed without a value: the last commit, so only
+// uncommitted changes are validated
+const changedBaseDefault = "HEAD"
+
+// stagedSources maps the files selected by --staged or --commits, and the files their
+// modules load, to temporary copies in stagedDir of their content in the index or, with
+// --commits, in stagedRev. Checks read a file through sourceFile so they see what will be
+// committed or pushed, while findings keep the file's real name.
+var (
+	stagedSources = map[string]string{}
+	stagedDir     string
+	stagedRev     string
+	stagedMu      sync.Mutex
+)
+
+// sourceFile returns the path holding the content to validate for a file
+func sourceFile(name string) string {
+	stagedMu.Lock()
+	defer stagedMu.Unlock()
+	if path, ok := stagedSources[name]; ok {
+		return path
+	}
+	return name
+}
+
+// runGit runs git with args in the current directory and returns its standard output. A
+// failing git command is a usage error, such as an unknown ref or a directory outside a
+// repository; git missing from the PATH is an environment error.
+func runGit(args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, exitError(exitEnvironment, errors.New("git not found"))
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, exitError(exitUsage, fmt.Errorf("git %s: %s", args[0], message))
+	}
+	return output, nil
+}
+
+// splitGitPaths splits the NUL-separated paths git prints with -z
+func splitGitPaths(output []byte) []string {
+	var paths []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths = append(paths, filepath.FromSlash(path))
+		}
+	}
+	return paths
+}
+
+// findChangedFiles returns the PowerShell files below the current directory that were
+// added, modified or renamed since the merge base of base and HEAD, committed or not, and
+// the untracked ones. Renamed files are listed under their new name.
+func findChangedFiles(base string) ([]string, error) {
+	since := base
+	if output, err := runGit("merge-base", base, "HEAD"); err == nil {
+		since = strings.TrimSpace(string(output))
+	}
+	changed, err := runGit("diff", "--name-only", "-z", "--relative", "--find-renames", "--diff-filter=ACMR", since, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit("ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return selectChangedFiles(append(splitGitPaths(changed), splitGitPaths(untracked)...)), nil
+}
+
+// findStagedFiles returns the PowerShell files below the current directory that are added,
+// modified or renamed in the index, and writes their staged content to dir for sourceFile.
+// Files the staged modules load are written there later, by stageFile.
+func findStagedFiles(dir string) ([]string, error) {
+	staged, err := runGit("diff", "--cached", "--name-only", "-z", "--relative", "--find-renames", "--diff-filter=ACMR", "--")
+	if err != nil {
+		return nil, err
+	}
+
+	return copyFilesAt(selectChangedFiles(splitGitPaths(staged)), "", dir)
+}
+
+// findCommittedFiles returns the PowerShell files below the current directory that a range
+// of commits such as origin/main..HEAD adds, modifies or renames, or every one for a single
+// commit, and writes their content as of the range's last commit to dir for sourceFile
+func findCommittedFiles(commits, dir string) ([]string, error) {
+	var listed []byte
+	var err error
+	rev := commits
+	if from, to, ok := strings.Cut(commits, ".."); ok {
+		rev = to
+		if rev == "" {
+			rev = "HEAD"
+		}
+		listed, err = runGit("diff", "--name-only", "-z", "--relative", "--find-renames", "--diff-filter=ACMR", from, rev, "--")
+	} else {
+		listed, err = runGit("ls-tree", "-r", "-z", "--name-only", rev)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return copyFilesAt(selectChangedFiles(splitGitPaths(listed)), rev, dir)
+}
+
+// copyFilesAt writes the content of files in rev, or in the index when rev is "", to dir
+// and has sourceFile and stageFile read them from there
+func copyFilesAt(files []string, rev, dir string) ([]string, error) {
+	stagedDir, stagedRev = dir, rev
+	for _, file := range files {
+		// Keep the file's name, since checks depend on its extension
+		content, err := runGit("show", stagedRev+":./"+filepath.ToSlash(file))
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, file)
+		if err := writeFileWithDirs(path, content); err != nil {
+			return nil, err
+		}
+		stagedSources[file] = path
+	}
+	return files, nil
+}
+
+// stageFile returns the path holding the staged or committed content of a file --staged or
+// --commits did not select, such as a script a selected module loads, writing it on first
+// use. A file missing from the index or commit maps to a path that does not exist, since it
+// will not be committed or pushed. Without either flag, and for a file outside the
+// directory, the file itself is returned.
+func stageFile(name string) string {
+	stagedMu.Lock()
+	defer stagedMu.Unlock()
+	if path, ok := stagedSources[name]; ok {
+		return path
+	}
+	if stagedDir == "" {
+		return name
+	}
+	path := filepath.Join(stagedDir, name)
+	if !isWithin(stagedDir, path) {
+		return name
+	}
+	if content, err := runGit("show", stagedRev+":./"+filepath.ToSlash(name)); err == nil {
+		if err := writeFileWithDirs(path, content); err != nil {
+			return name
+		}
+	}
+	stagedSources[name] = path
+	return path
+}
+
+// selectChangedFiles keeps the changed files validate would find by searching the directory:
+// PowerShell files that are not hidden, ignored or excluded, in directories that are not
+func selectChangedFiles(paths []string) []string {
+	discovery := newFileDiscovery()
+	var files []string
+	seen := map[string]bool{}
+	for _, path := range paths {
+		if seen[path] || !isPowerShellFile(path) || discovery.pathSkipReason(path, false) != "" {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+	return files
+}
+
+// selectValidationFiles returns the files to validate: every PowerShell file, or with
+// --changed, --staged or --commits only those git reports as changed. The returned cleanup
+// removes the staged or committed copies.
+func selectValidationFiles() ([]string, func(), error) {
+	cleanup := func() {}
+	switch {
+	case validateStaged || validateCommits != "":
+		dir, err := os.MkdirTemp("", appDirName+"-staged-")
+		if err != nil {
+			return nil, cleanup, err
+		}
+		cleanup = func() {
+			stagedSources, stagedDir, stagedRev = map[string]string{}, "", ""
+			os.RemoveAll(dir)
+		}
+		var files []string
+		if validateStaged {
+			files, err = findStagedFiles(dir)
+		} else {
+			files, err = findCommittedFiles(validateCommits, dir)
+		}
+		return files, cleanup, err
+	case validateChanged != "":
+		files, err := findChangedFiles(validateChanged)
+		return files, cleanup, err
+	}
+	return findPowerShellFiles(), cleanup, nil
+}
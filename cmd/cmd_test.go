@@ -1,7 +1,18 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"unicode/utf16"
 )
 
 func TestSetVersionInfo(t *testing.T) {
@@ -29,6 +40,21 @@ func TestSetVersionInfo(t *testing.T) {
 	}
 }
 
+func TestWelcomeCommands(t *testing.T) {
+	for _, command := range rootCmd.Commands() {
+		if !command.IsAvailableCommand() || command.Name() == "completion" {
+			continue
+		}
+		key := "root.command." + command.Name()
+		if lookupMessage(defaultLocale, key) == key {
+			t.Errorf("Expected a %s message for the welcome screen", key)
+		}
+		if _, ok := commandIcons[command.Name()]; !ok {
+			t.Errorf("Expected an icon for %s on the welcome screen", command.Name())
+		}
+	}
+}
+
 // TestExecute is removed because testing Execute function directly
 // would cause the CLI to run, which is not suitable for unit tests.
 // The Execute function is tested indirectly through integration tests.
@@ -59,3 +85,2071 @@ func TestGetAllCoursesInfo(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchHints(t *testing.T) {
+	matches := SearchHints("that pipeline thing")
+	if len(matches) == 0 {
+		t.Fatal("Expected matches for 'pipeline', got none")
+	}
+	if matches[0].Hint.Title != "Pipeline Basics" {
+		t.Errorf("Expected top match 'Pipeline Basics', got %s", matches[0].Hint.Title)
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score > matches[i-1].Score {
+			t.Errorf("Results not ranked: %d scored above %d", matches[i].Score, matches[i-1].Score)
+		}
+	}
+
+	matches = SearchHints("Where-Object")
+	if len(matches) == 0 || matches[0].Hint.Title != "Filtering Objects" {
+		t.Errorf("Expected 'Filtering Objects' as top match for Where-Object, got %v", matches)
+	}
+
+	if matches := SearchHints("kubernetes"); len(matches) != 0 {
+		t.Errorf("Expected no matches for unrelated query, got %d", len(matches))
+	}
+	if err := showHintSearch("kubernetes", 0); ExitCode(err) != exitFailure {
+		t.Errorf("Expected a search without results to exit with %d, got %v", exitFailure, err)
+	}
+}
+
+func TestDetectLocale(t *testing.T) {
+	env := map[string]string{"LANG": "pt_BR.UTF-8"}
+	getenv := func(key string) string { return env[key] }
+
+	if got := detectLocale("", getenv); got != "pt" {
+		t.Errorf("Expected pt from LANG, got %s", got)
+	}
+	if got := detectLocale("de", getenv); got != "de" {
+		t.Errorf("Expected --lang to win, got %s", got)
+	}
+	env["LC_ALL"] = "es_ES.UTF-8"
+	if got := detectLocale("", getenv); got != "es" {
+		t.Errorf("Expected LC_ALL to win over LANG, got %s", got)
+	}
+	if got := detectLocale("", func(string) string { return "C" }); got != defaultLocale {
+		t.Errorf("Expected fallback to %s for C locale, got %s", defaultLocale, got)
+	}
+	if got := detectLocale("xx", func(string) string { return "" }); got != defaultLocale {
+		t.Errorf("Expected fallback to %s for unknown language, got %s", defaultLocale, got)
+	}
+}
+
+func TestTranslationFallback(t *testing.T) {
+	defer func() { currentLocale = defaultLocale }()
+
+	currentLocale = "de"
+	if got := T("validate.found_files", 2); got != "🔍 2 PowerShell-Datei(en) zur Validierung gefunden:" {
+		t.Errorf("Unexpected German message: %s", got)
+	}
+	if got := T("does.not.exist"); got != "does.not.exist" {
+		t.Errorf("Expected unknown key to be returned as-is, got %s", got)
+	}
+
+	currentLocale = "en"
+	if got := T("validate.found_files", 2); got != "🔍 Found 2 PowerShell file(s) to validate:" {
+		t.Errorf("Unexpected English message: %s", got)
+	}
+}
+
+func TestTranslationCatalogsConsistent(t *testing.T) {
+	if _, err := loadCatalogs(); err != nil {
+		t.Fatalf("Failed to load locale catalogs: %v", err)
+	}
+	for _, locale := range availableLocales() {
+		report, err := checkTranslations(locale)
+		if err != nil {
+			t.Fatalf("checkTranslations(%s) failed: %v", locale, err)
+		}
+		if len(report.UnknownMessages) > 0 {
+			t.Errorf("%s has messages missing from the English catalog: %v", locale, report.UnknownMessages)
+		}
+		if len(report.MismatchedArgs) > 0 {
+			t.Errorf("%s has messages with mismatched format arguments: %v", locale, report.MismatchedArgs)
+		}
+	}
+}
+
+func TestParseAboutTopic(t *testing.T) {
+	classic := "TOPIC\n    about_Pipelines\n\nSHORT DESCRIPTION\n    Combining commands into pipelines\n\nLONG DESCRIPTION\n    A pipeline is a series of commands\n    connected by pipeline operators.\n\nSEE ALSO\n    about_Functions\n"
+	about := parseAboutTopic("about_Pipelines", classic)
+	if about.Synopsis != "Combining commands into pipelines" {
+		t.Errorf("Unexpected synopsis: %q", about.Synopsis)
+	}
+	if len(about.Sections) != 2 || about.Sections[0].Title != "LONG DESCRIPTION" || about.Sections[1].Title != "SEE ALSO" {
+		t.Fatalf("Unexpected sections: %+v", about.Sections)
+	}
+
+	markdown := "# about_Functions\n\n## Short description\nDescribes how to create functions.\n\n## Long description\nA function is a list of statements.\n"
+	about = parseAboutTopic("about_Functions", markdown)
+	if about.Synopsis != "Describes how to create functions." {
+		t.Errorf("Unexpected synopsis: %q", about.Synopsis)
+	}
+	if len(about.Sections) != 1 || about.Sections[0].Title != "Long description" {
+		t.Errorf("Unexpected sections: %+v", about.Sections)
+	}
+}
+
+func TestPowerShellScriptCommandKeepsPathsAsArguments(t *testing.T) {
+	names := []string{`it's $(whoami); "x".ps1`, "-Force.ps1", "my script.ps1"}
+	paths, err := scriptPathArgs(names)
+	if err != nil {
+		t.Fatalf("scriptPathArgs failed: %v", err)
+	}
+
+	cmd := powerShellScriptCommand("pwsh", "/tmp/parse.ps1", paths...)
+	args := cmd.Args[len(cmd.Args)-len(names):]
+	for i, name := range names {
+		if !filepath.IsAbs(args[i]) || filepath.Base(args[i]) != name {
+			t.Errorf("Expected %q as a separate absolute argument, got %q", name, args[i])
+		}
+	}
+	script := cmd.Args[len(cmd.Args)-len(names)-1]
+	if script != "/tmp/parse.ps1" || cmd.Args[len(cmd.Args)-len(names)-2] != "-File" {
+		t.Errorf("Expected -File /tmp/parse.ps1 before the paths, got %v", cmd.Args)
+	}
+}
+
+func TestParsePowerShellFilesWithHostileNames(t *testing.T) {
+	if !isPowerShellAvailable() {
+		t.Skip("PowerShell is not installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// Run from a temporary directory so an injected New-Item would create the canary there
+	dir := t.TempDir()
+	t.Chdir(dir)
+	names := []string{
+		`it's $(New-Item canary); "x".ps1`,
+		"x'; New-Item canary; '.ps1",
+		"-Force.ps1",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(name, []byte("Get-Date\n"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	trees, err := parsePowerShellFiles(names)
+	if err != nil {
+		t.Fatalf("parsePowerShellFiles failed: %v", err)
+	}
+	for _, name := range names {
+		if trees[name] == nil || len(trees[name].Diagnostics) != 0 {
+			t.Errorf("Expected a tree without diagnostics for %q, got %v", name, trees[name])
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "canary")); err == nil {
+		t.Error("A file name was executed as PowerShell code")
+	}
+}
+
+func TestSelectHintForFindings(t *testing.T) {
+	findings := []CodeFinding{
+		{Kind: findingWriteHost, File: "b.ps1", Line: 7},
+		{Kind: findingEmptyCatch, File: "a.ps1", Line: 12},
+		{Kind: findingWriteHost, File: "a.ps1", Line: 3},
+	}
+
+	suggestion := selectHintForFindings(findings, "fundamentals")
+	if suggestion == nil || suggestion.Hint.ID != "functions.output" {
+		t.Fatalf("Expected the hint with the most findings, got %+v", suggestion)
+	}
+	if len(suggestion.Triggers) != 2 || suggestion.Triggers[0].File != "a.ps1" || suggestion.Triggers[0].Line != 3 {
+		t.Errorf("Expected triggers ordered by file and line, got %+v", suggestion.Triggers)
+	}
+	if len(suggestion.Others) != 1 || suggestion.Others[0].Kind != findingEmptyCatch {
+		t.Errorf("Expected the empty catch as another finding, got %+v", suggestion.Others)
+	}
+
+	suggestion = selectHintForFindings(findings, "automation")
+	if suggestion == nil || suggestion.Hint.ID != "automation.error-handling" {
+		t.Errorf("Expected the current course's hint to be preferred, got %+v", suggestion)
+	}
+
+	if suggestion := selectHintForFindings(nil, "functions"); suggestion != nil {
+		t.Errorf("Expected no suggestion without findings, got %+v", suggestion)
+	}
+}
+
+func TestCodeFindings(t *testing.T) {
+	tree := &SyntaxTree{
+		File: "script.ps1",
+		Functions: []FunctionNode{
+			{Extent: Extent{Line: 1, Column: 10}, Name: "Get-Thing", HasParamBlock: true},
+			{Extent: Extent{Line: 5, Column: 10}, Name: "Set-Thing"},
+		},
+		Commands: []CommandNode{{Extent: Extent{Line: 3, Column: 15}, Name: "Write-Host"}},
+		Tokens: []Token{
+			{Extent: Extent{Line: 7, Column: 1}, Kind: "Try"},
+			{Extent: Extent{Line: 7, Column: 5}, Kind: "LCurly"},
+			{Extent: Extent{Line: 7, Column: 7}, Kind: "RCurly"},
+			{Extent: Extent{Line: 8, Column: 1}, Kind: "Catch"},
+			{Extent: Extent{Line: 8, Column: 7}, Kind: "LBracket"},
+			{Extent: Extent{Line: 8, Column: 8}, Kind: "Identifier"},
+			{Extent: Extent{Line: 8, Column: 19}, Kind: "RBracket"},
+			{Extent: Extent{Line: 8, Column: 21}, Kind: "LCurly"},
+			{Extent: Extent{Line: 8, Column: 23}, Kind: "Comment"},
+			{Extent: Extent{Line: 9, Column: 1}, Kind: "RCurly"},
+			{Extent: Extent{Line: 10, Column: 1}, Kind: "Catch"},
+			{Extent: Extent{Line: 10, Column: 7}, Kind: "LCurly"},
+			{Extent: Extent{Line: 10, Column: 9}, Kind: "Throw"},
+			{Extent: Extent{Line: 10, Column: 15}, Kind: "RCurly"},
+		},
+	}
+
+	findings := codeFindings(tree)
+	want := []CodeFinding{
+		{Kind: findingMissingCmdletBinding, File: "script.ps1", Line: 1, Column: 10, Name: "Get-Thing"},
+		{Kind: findingWriteHost, File: "script.ps1", Line: 3, Column: 15},
+		{Kind: findingMissingParamBlock, File: "script.ps1", Line: 5, Column: 10, Name: "Set-Thing"},
+		{Kind: findingEmptyCatch, File: "script.ps1", Line: 8, Column: 1},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %+v", len(want), findings)
+	}
+	for i := range want {
+		if findings[i] != want[i] {
+			t.Errorf("Finding %d: expected %+v, got %+v", i, want[i], findings[i])
+		}
+	}
+
+	tree.Suppressions = []SuppressMessageNode{{Rule: "BP002", Scope: Extent{Line: 1, Column: 1, EndLine: 4, EndColumn: 2}}}
+	for _, finding := range codeFindings(tree) {
+		if finding.Kind == findingWriteHost {
+			t.Errorf("Expected the suppressed Write-Host to be left out, got %+v", finding)
+		}
+	}
+}
+
+func TestSourceSnippet(t *testing.T) {
+	source := []string{"# comment", "\tif ($x -eq) {", "}"}
+	diagnostic := SyntaxDiagnostic{File: "a.ps1", Line: 2, Column: 11, EndLine: 2, EndColumn: 12}
+
+	snippet := sourceSnippet(diagnostic, source)
+	expected := []string{
+		"  |",
+		"2 | \tif ($x -eq) {",
+		"  | \t         ^",
+	}
+	if len(snippet) != len(expected) {
+		t.Fatalf("Expected %d lines, got %v", len(expected), snippet)
+	}
+	for i := range expected {
+		if snippet[i] != expected[i] {
+			t.Errorf("Line %d: expected %q, got %q", i, expected[i], snippet[i])
+		}
+	}
+
+	diagnostic = SyntaxDiagnostic{Line: 2, Column: 2, EndLine: 2, EndColumn: 4}
+	if got := caretLine(source[1], diagnostic); got != "\t^^" {
+		t.Errorf("Expected caret to underline the extent, got %q", got)
+	}
+
+	if snippet := sourceSnippet(SyntaxDiagnostic{Line: 10}, source); snippet != nil {
+		t.Errorf("Expected no snippet for a line outside the file, got %v", snippet)
+	}
+}
+
+func TestAnalyzerSeverity(t *testing.T) {
+	cases := map[string]string{
+		"Error":       severityError,
+		"ParseError":  severityError,
+		"Warning":     severityWarning,
+		"Information": severityInfo,
+		"":            severityInfo,
+	}
+	for input, want := range cases {
+		if got := analyzerSeverity(input); got != want {
+			t.Errorf("analyzerSeverity(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestMergeFindings(t *testing.T) {
+	builtin := []Finding{
+		{RuleID: "BP001", Severity: severityInfo, Source: sourceBuiltin},
+		{RuleID: "BP002", Severity: severityInfo, Source: sourceBuiltin},
+	}
+	analyzer := []Finding{
+		{RuleID: "PSAvoidUsingWriteHost", Severity: severityWarning, Line: 9, Column: 1, Source: sourceAnalyzer},
+		{RuleID: "PSAvoidUsingPlainTextForPassword", Severity: severityError, Line: 2, Column: 5, Source: sourceAnalyzer},
+	}
+
+	merged := mergeFindings(builtin, analyzer)
+	var ids []string
+	for _, finding := range merged {
+		ids = append(ids, finding.RuleID)
+	}
+	want := []string{"PSAvoidUsingPlainTextForPassword", "PSAvoidUsingWriteHost", "BP001"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+}
+
+func TestResolveAnalyzerSettings(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if settings, err := resolveAnalyzerSettings(""); err != nil || settings != "" {
+		t.Errorf("Expected no settings without a settings file, got %q, %v", settings, err)
+	}
+	if settings, err := resolveAnalyzerSettings("PSGallery"); err != nil || settings != "PSGallery" {
+		t.Errorf("Expected the preset name, got %q, %v", settings, err)
+	}
+	if _, err := resolveAnalyzerSettings("missing.psd1"); err == nil {
+		t.Error("Expected an error for a missing settings file")
+	}
+
+	if err := os.WriteFile(analyzerSettingsFile, []byte("@{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := resolveAnalyzerSettings("")
+	if err != nil || !filepath.IsAbs(settings) || filepath.Base(settings) != analyzerSettingsFile {
+		t.Errorf("Expected the absolute path of %s, got %q, %v", analyzerSettingsFile, settings, err)
+	}
+}
+
+func TestPrintTestResults(t *testing.T) {
+	tests := []TestResult{
+		{Name: "returns a greeting", Path: "Get-Greeting", File: "Greeting.Tests.ps1", Line: 4, Result: "Passed", DurationMs: 12},
+		{Name: "handles empty names", Path: "Get-Greeting", File: "Greeting.Tests.ps1", Line: 9, Result: "Failed", DurationMs: 1500, Message: "Expected 'Hello', but got $null."},
+		{Name: "runs on Windows", Path: "Get-Greeting", File: "Greeting.Tests.ps1", Line: 14, Result: "Skipped"},
+	}
+
+	var out strings.Builder
+	summary := printTestResults(&out, tests)
+	if summary.Total != 3 || summary.Passed != 1 || summary.Failed != 1 || summary.Skipped != 1 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	for _, want := range []string{
+		"📄 Greeting.Tests.ps1",
+		"✅ Get-Greeting returns a greeting (12 ms)",
+		"❌ Get-Greeting handles empty names (1.50 s)",
+		"Expected 'Hello', but got $null.",
+		"--> Greeting.Tests.ps1:9",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Count(out.String(), "📄") != 1 {
+		t.Errorf("Expected one file header, got:\n%s", out.String())
+	}
+}
+
+func TestBuildSARIF(t *testing.T) {
+	// Outside a repository, files are located relative to the current directory
+	t.Chdir(t.TempDir())
+	results := []FileResult{
+		{File: filepath.Join("scripts", "my script.ps1"), Findings: []Finding{
+			syntaxFinding(SyntaxDiagnostic{File: "scripts/my script.ps1", Line: 3, Column: 14, EndLine: 3, EndColumn: 15, Message: "Missing closing '}'", ErrorID: "MissingEndCurlyBrace"}),
+		}},
+		{File: "deploy.ps1", Valid: true, Findings: []Finding{
+			{RuleID: "PSAvoidUsingWriteHost", Severity: severityWarning, Category: categoryBestPractices, File: "deploy.ps1", Line: 7, Column: 1, Message: "Avoid Write-Host", Source: sourceAnalyzer},
+			{RuleID: "BP001", Severity: severityInfo, Category: categoryBestPractices, File: "deploy.ps1", Message: "Consider [CmdletBinding()]", Source: sourceBuiltin},
+		}},
+		{File: "broken.ps1", Checks: []CheckResult{{Name: categorySyntax, Status: checkError, Problem: "PowerShell did not respond"}}},
+	}
+
+	log := buildSARIF(results)
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected log: %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(run.Results))
+	}
+
+	for _, result := range run.Results {
+		if rule := run.Tool.Driver.Rules[result.RuleIndex]; rule.ID != result.RuleID {
+			t.Errorf("Result %s points at rule %s", result.RuleID, rule.ID)
+		}
+	}
+
+	syntax := run.Results[0]
+	location := syntax.Locations[0].PhysicalLocation
+	if syntax.Level != "error" || location.ArtifactLocation.URI != "scripts/my%20script.ps1" {
+		t.Errorf("Unexpected syntax result: %+v", syntax)
+	}
+	if location.Region == nil || location.Region.StartLine != 3 || location.Region.StartColumn != 14 || location.Region.EndColumn != 15 {
+		t.Errorf("Unexpected region: %+v", location.Region)
+	}
+	if run.Results[1].Level != "warning" || run.Results[2].Level != "note" {
+		t.Errorf("Unexpected levels: %s, %s", run.Results[1].Level, run.Results[2].Level)
+	}
+	if run.Results[2].Locations[0].PhysicalLocation.Region != nil {
+		t.Error("Expected no region for a finding without a line")
+	}
+
+	analyzerRule := run.Tool.Driver.Rules[run.Results[1].RuleIndex]
+	if analyzerRule.HelpURI != analyzerRuleHelpBase+"avoidusingwritehost" {
+		t.Errorf("Unexpected analyzer rule help URI: %s", analyzerRule.HelpURI)
+	}
+
+	invocation := run.Invocations[0]
+	if invocation.ExecutionSuccessful || len(invocation.ToolExecutionNotifications) != 1 {
+		t.Errorf("Expected the problem as a failed invocation notification, got %+v", invocation)
+	}
+}
+
+func TestBuildSARIFFromSubdirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	t.Chdir(root)
+	if output, err := exec.Command("git", "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	course := filepath.Join("course-2-pipelines-filtering", "scripts")
+	if err := os.MkdirAll(course, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(root, "course-2-pipelines-filtering"))
+
+	log := buildSARIF([]FileResult{{File: filepath.Join("scripts", "a.ps1"), Findings: []Finding{{RuleID: "BP001", Severity: severityInfo, Line: 1}}}})
+	location := log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation
+	if location.URI != "course-2-pipelines-filtering/scripts/a.ps1" || location.URIBaseID != "%SRCROOT%" {
+		t.Errorf("Expected a URI relative to the repository root, got %+v", location)
+	}
+}
+
+func sampleValidationReport() ValidationReport {
+	return ValidationReport{
+		Files: []FileResult{
+			{File: "broken.ps1", Checks: []CheckResult{
+				{Name: categorySyntax, Status: checkFailed},
+				{Name: categoryCrossPlatform, Status: checkSkipped},
+				{Name: categoryBestPractices, Status: checkSkipped},
+			}, Findings: []Finding{
+				{RuleID: "SY001", Severity: severityError, Category: categorySyntax, File: "broken.ps1", Line: 3, Column: 14, Message: "Missing closing '}'", Source: sourceBuiltin},
+			}},
+			{File: "Greeting.Tests.ps1", Valid: true, Checks: []CheckResult{
+				{Name: categorySyntax, Status: checkPassed},
+				{Name: categoryCrossPlatform, Status: checkWarning},
+				{Name: categoryBestPractices, Status: checkPassed, Problem: "PSScriptAnalyzer failed"},
+			}, Findings: []Finding{
+				{RuleID: "CP001", Severity: severityWarning, Category: categoryCrossPlatform, File: "Greeting.Tests.ps1", Line: 2, Column: 7, Message: "'Get-Service' may not work on all platforms", Source: sourceBuiltin},
+			}},
+		},
+		TestsRun: true,
+		Tests: []TestResult{
+			{Name: "greets", Path: "Get-Greeting", File: "Greeting.Tests.ps1", Line: 4, Result: "Passed", DurationMs: 12},
+			{Name: "fails", Path: "Get-Greeting", File: "Greeting.Tests.ps1", Line: 9, Result: "Failed", DurationMs: 1500, Message: "Expected 1\nbut got 2"},
+			{Name: "legacy", Result: "Skipped"},
+		},
+	}
+}
+
+func TestBuildJUnit(t *testing.T) {
+	suites := buildJUnit(sampleValidationReport())
+	if len(suites.Suites) != 3 {
+		t.Fatalf("Expected a suite per file plus one for unattributed tests, got %d", len(suites.Suites))
+	}
+	if suites.Tests != 9 || suites.Failures != 2 || suites.Errors != 0 || suites.Skipped != 3 {
+		t.Errorf("Unexpected totals: tests=%d failures=%d errors=%d skipped=%d", suites.Tests, suites.Failures, suites.Errors, suites.Skipped)
+	}
+
+	broken := suites.Suites[0]
+	if broken.Cases[0].Failure == nil || !strings.Contains(broken.Cases[0].Failure.Text, "broken.ps1:3:14: error") {
+		t.Errorf("Expected the syntax error as a failure, got %+v", broken.Cases[0])
+	}
+	if broken.Cases[1].Skipped == nil {
+		t.Error("Expected the checks after a syntax error to be skipped")
+	}
+
+	tests := suites.Suites[1]
+	if tests.Cases[1].SystemOut == "" || tests.Cases[1].Failure != nil {
+		t.Errorf("Expected warnings in the output of a passing test case, got %+v", tests.Cases[1])
+	}
+	if len(tests.Cases) != 5 || tests.Cases[4].Failure == nil || tests.Cases[4].Failure.Message != "Expected 1" || tests.Cases[4].Time != "1.500" {
+		t.Errorf("Expected the failed Pester test in its file's suite, got %+v", tests.Cases)
+	}
+	if suites.Suites[2].Name != pesterSuiteName || suites.Suites[2].Cases[0].Skipped == nil {
+		t.Errorf("Expected the unattributed skipped test in the Pester suite, got %+v", suites.Suites[2])
+	}
+
+	var out strings.Builder
+	if err := writeJUnit(&out, sampleValidationReport()); err != nil {
+		t.Fatalf("writeJUnit failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "<?xml") || !strings.Contains(out.String(), `<testsuite name="broken.ps1" tests="3" failures="1" errors="0" skipped="2">`) {
+		t.Errorf("Unexpected JUnit XML:\n%s", out.String())
+	}
+}
+
+func TestWriteJSONMatchesSchema(t *testing.T) {
+	var out strings.Builder
+	if err := writeJSON(&out, sampleValidationReport()); err != nil {
+		t.Fatalf("writeJSON failed: %v", err)
+	}
+	var report map[string]interface{}
+	if err := json.Unmarshal([]byte(out.String()), &report); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("..", "docs", "validate-report.schema.json"))
+	if err != nil {
+		t.Fatalf("Failed to read the schema: %v", err)
+	}
+	var schema struct {
+		Required   []string `json:"required"`
+		Properties map[string]struct {
+			Required []string `json:"required"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Invalid schema: %v", err)
+	}
+	for _, key := range schema.Required {
+		if _, ok := report[key]; !ok {
+			t.Errorf("Report is missing required property %q", key)
+		}
+	}
+	summary := report["summary"].(map[string]interface{})
+	for _, key := range schema.Properties["summary"].Required {
+		if _, ok := summary[key]; !ok {
+			t.Errorf("Summary is missing required property %q", key)
+		}
+	}
+
+	if report["valid"] != false || summary["errors"] != 1.0 || summary["warnings"] != 1.0 || summary["failedTests"] != 1.0 {
+		t.Errorf("Unexpected report: valid=%v summary=%v", report["valid"], summary)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("unknown flag: --nope"), exitUsage},
+		{exitError(exitFailure, errors.New("2 of 3 files failed validation")), exitFailure},
+		{reportedError(exitNotInCourse, "no current course"), exitNotInCourse},
+		{reported(errPowerShellNotFound), exitEnvironment},
+		{fmt.Errorf("wrapped: %w", exitError(exitEnvironment, errors.New("Pester is not installed"))), exitEnvironment},
+	}
+	for _, c := range cases {
+		if got := ExitCode(c.err); got != c.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", c.err, got, c.want)
+		}
+	}
+
+	if IsReported(exitError(exitFailure, errors.New("failed"))) || !IsReported(reported(errors.New("failed"))) {
+		t.Error("Expected only reported errors to be marked as reported")
+	}
+}
+
+func TestCheckStatusFailOn(t *testing.T) {
+	defer func(failOn string) { validateFailOn = failOn }(validateFailOn)
+	warnings := []Finding{{RuleID: "CP001", Severity: severityWarning}, {RuleID: "BP002", Severity: severityInfo}}
+	errs := []Finding{{RuleID: "SY001", Severity: severityError}}
+
+	validateFailOn = severityError
+	if got := checkStatus(warnings); got != checkWarning {
+		t.Errorf("Expected warnings to only warn with --fail-on error, got %s", got)
+	}
+	if got := checkStatus(errs); got != checkFailed {
+		t.Errorf("Expected errors to fail with --fail-on error, got %s", got)
+	}
+
+	validateFailOn = severityWarning
+	if got := checkStatus(warnings); got != checkFailed {
+		t.Errorf("Expected warnings to fail with --fail-on warning, got %s", got)
+	}
+	if got := checkStatus([]Finding{{RuleID: "BP002", Severity: severityInfo}}); got != checkWarning {
+		t.Errorf("Expected information to never fail, got %s", got)
+	}
+}
+
+func TestRunRules(t *testing.T) {
+	// The tree of:
+	//   # Get-Service is Windows-only
+	//   function Get-Report { param() Write-Host "function"; Microsoft.PowerShell.Management\Get-Service }
+	//   function Save { Set-Content -Path C:\Temp\out.txt -Value 'no C: here' }
+	tree := &SyntaxTree{
+		File: "report.ps1",
+		Tokens: []Token{
+			{Extent: Extent{1, 1, 1, 30}, Kind: "Comment", Text: "# Get-Service is Windows-only"},
+			{Extent: Extent{2, 44, 2, 54}, Kind: "StringExpandable", Text: `"function"`, Value: "function"},
+			{Extent: Extent{3, 36, 3, 51}, Kind: "Generic", Text: `C:\Temp\out.txt`, Value: `C:\Temp\out.txt`},
+			{Extent: Extent{3, 59, 3, 71}, Kind: "StringLiteral", Text: "'no C: here'", Value: "no C: here"},
+		},
+		Commands: []CommandNode{
+			{Extent: Extent{2, 33, 2, 43}, Name: "Write-Host"},
+			{Extent: Extent{2, 56, 2, 98}, Name: `Microsoft.PowerShell.Management\Get-Service`},
+			{Extent: Extent{3, 17, 3, 28}, Name: "Set-Content"},
+		},
+		Functions: []FunctionNode{
+			{Extent: Extent{2, 10, 2, 20}, Name: "Get-Report", HasParamBlock: true},
+			{Extent: Extent{3, 10, 3, 14}, Name: "Save"},
+		},
+	}
+
+	var got []string
+	for _, category := range []string{categoryCrossPlatform, categoryBestPractices} {
+		for _, finding := range runRules(tree, category) {
+			if finding.File != "report.ps1" || finding.Source != sourceBuiltin {
+				t.Errorf("Unexpected finding %+v", finding)
+			}
+			got = append(got, fmt.Sprintf("%s %d:%d-%d:%d", finding.RuleID, finding.Line, finding.Column, finding.EndLine, finding.EndColumn))
+		}
+	}
+	want := []string{
+		"CP001 2:56-2:98",
+		"CP002 3:36-3:51",
+		"BP001 2:10-2:20",
+		"BP002 2:33-2:43",
+		"BP003 3:10-3:14",
+	}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected findings %v, got %v", want, got)
+	}
+}
+
+func TestParseSyntaxTree(t *testing.T) {
+	if !isPowerShellAvailable() {
+		t.Skip("PowerShell is not installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	source := "# Get-Service in a comment\nfunction Get-Report {\n    [CmdletBinding()]\n    param()\n    Write-Output 'Get-Service'\n    Get-Service\n}\n"
+	if err := os.WriteFile("report.ps1", []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := parsePowerShellFile("report.ps1")
+	if err != nil {
+		t.Fatalf("parsePowerShellFile failed: %v", err)
+	}
+	findings := runRules(tree, categoryCrossPlatform)
+	if len(findings) != 1 || findings[0].RuleID != "CP001" || findings[0].Line != 6 || findings[0].Column != 5 {
+		t.Errorf("Expected a single CP001 finding at 6:5, got %+v", findings)
+	}
+	if len(tree.Functions) != 1 || tree.Functions[0].Name != "Get-Report" || tree.Functions[0].Line != 2 || tree.Functions[0].Column != 10 || !tree.Functions[0].HasCmdletBinding {
+		t.Errorf("Unexpected functions %+v", tree.Functions)
+	}
+	if findings := runRules(tree, categoryBestPractices); len(findings) != 0 {
+		t.Errorf("Expected no best practice findings, got %+v", findings)
+	}
+}
+
+func TestParseArgsAndAttributes(t *testing.T) {
+	if !isPowerShellAvailable() {
+		t.Skip("PowerShell is not installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	source := "function greet { \"Hi $($args[0])\" }\nfunction Get-Report {\n    [Alias('report')]\n    param()\n}\n"
+	if err := os.WriteFile("greet.ps1", []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tree, err := parsePowerShellFile("greet.ps1")
+	if err != nil {
+		t.Fatalf("parsePowerShellFile failed: %v", err)
+	}
+	if len(tree.Functions) != 2 || !tree.Functions[0].UsesArgs || tree.Functions[1].UsesArgs {
+		t.Errorf("Expected only greet to read $args, got %+v", tree.Functions)
+	}
+	if len(tree.Attributes) != 1 || tree.Attributes[0].Line != 3 || tree.Attributes[0].Column != 5 {
+		t.Errorf("Expected the [Alias()] attribute, got %+v", tree.Attributes)
+	}
+}
+
+func TestParseDotSources(t *testing.T) {
+	if !isPowerShellAvailable() {
+		t.Skip("PowerShell is not installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	source := ". $PSScriptRoot/Public/Get-Farewell.ps1\n. \"$PSScriptRoot\\Private\\Format-Name.ps1\"\nforeach ($file in Get-ChildItem $PSScriptRoot/Public) { . $file.FullName }\n"
+	if err := os.WriteFile("Greeting.psm1", []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tree, err := parsePowerShellFile("Greeting.psm1")
+	if err != nil {
+		t.Fatalf("parsePowerShellFile failed: %v", err)
+	}
+	var got []string
+	for _, dotSource := range tree.DotSources {
+		got = append(got, dotSource.Path)
+	}
+	if want := []string{"Public/Get-Farewell.ps1", `Private\Format-Name.ps1`, ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected dot-sourced paths %q, got %q", want, got)
+	}
+}
+
+func TestParseSuppressionScope(t *testing.T) {
+	if !isPowerShellAvailable() {
+		t.Skip("PowerShell is not installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	source := "function Get-Report {\n    [Diagnostics.CodeAnalysis.SuppressMessage('BP001', '')]\n    param()\n    'report'\n}\n"
+	if err := os.WriteFile("report.ps1", []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tree, err := parsePowerShellFile("report.ps1")
+	if err != nil {
+		t.Fatalf("parsePowerShellFile failed: %v", err)
+	}
+	result := &FileResult{File: tree.File, suppressions: findSuppressions(tree)}
+	kept := result.suppress(runRules(tree, categoryBestPractices))
+	for _, finding := range kept {
+		if finding.RuleID == "BP001" {
+			t.Errorf("Expected BP001 at the function name to be suppressed, got %+v", finding)
+		}
+	}
+	if unused := result.unusedSuppressions(); len(unused) != 0 {
+		t.Errorf("Expected the suppression to be used, got %+v", unused)
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	defer func(enabled bool) { analyzerEnabled = enabled }(analyzerEnabled)
+	analyzerEnabled = false
+
+	comment := func(line, column int, text string) Token {
+		return Token{Extent: Extent{line, column, line, column + len(text)}, Kind: "Comment", Text: text}
+	}
+	tree := &SyntaxTree{
+		File: "service.ps1",
+		Tokens: []Token{
+			comment(1, 1, "# pwsh-skills-disable-next-line CP001 -- course 4 manages services"),
+			comment(3, 14, "# pwsh-skills-disable-line BP002"),
+			comment(5, 1, "# pwsh-skills-disable CP002"),
+			comment(7, 1, "# pwsh-skills-enable CP002"),
+			comment(9, 1, "# pwsh-skills-disable-next-line BP001"),
+			comment(11, 1, "<# pwsh-skills-disable-file PSAvoidUsingPositionalParameters #>"),
+			comment(12, 1, "# a comment mentioning pwsh-skills-disable CP001"),
+		},
+		Suppressions: []SuppressMessageNode{
+			{Extent: Extent{14, 5, 14, 60}, Rule: "BP001", Scope: Extent{13, 1, 16, 2}},
+			{Extent: Extent{15, 5, 15, 60}, Rule: "PSAvoidUsingWriteHost", Scope: Extent{13, 1, 16, 2}},
+		},
+	}
+	finding := func(ruleID string, line int) Finding {
+		return Finding{RuleID: ruleID, Severity: severityWarning, File: tree.File, Line: line, Column: 1}
+	}
+
+	result := &FileResult{File: tree.File, suppressions: findSuppressions(tree)}
+	if len(result.suppressions) != 6 {
+		t.Fatalf("Expected 6 suppressions, got %d", len(result.suppressions))
+	}
+	kept := result.suppress([]Finding{
+		finding("CP001", 2),  // suppressed by the next-line comment
+		finding("CP001", 3),  // only the next line is covered
+		finding("BP002", 3),  // suppressed by the trailing comment
+		finding("CP002", 6),  // inside the disable block
+		finding("CP002", 8),  // after the enable
+		finding("BP001", 13), // at the name of the function with [SuppressMessage('BP001', '')]
+		finding("BP001", 17), // outside it
+	})
+	var got []string
+	for _, f := range kept {
+		got = append(got, fmt.Sprintf("%s@%d", f.RuleID, f.Line))
+	}
+	if want := "CP001@3 CP002@8 BP001@17"; strings.Join(got, " ") != want {
+		t.Errorf("Expected %s to be kept, got %v", want, got)
+	}
+	if len(result.Suppressed) != 4 {
+		t.Errorf("Expected 4 suppressed findings, got %v", result.Suppressed)
+	}
+
+	// The BP001 comment silenced nothing; the analyzer rule is not reported while the analyzer is off
+	unused := result.unusedSuppressions()
+	if len(unused) != 1 || unused[0].RuleID != "SP001" || unused[0].Line != 9 {
+		t.Errorf("Expected the BP001 suppression on line 9 to be unused, got %+v", unused)
+	}
+
+	validateIgnoreSuppressions = true
+	defer func() { validateIgnoreSuppressions = false }()
+	if kept := result.suppress([]Finding{finding("CP001", 2)}); len(kept) != 1 {
+		t.Error("Expected --ignore-suppressions to keep suppressed findings")
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.Tests.ps1", "src/Get-Thing.Tests.ps1", true},
+		{"*.tests.ps1", "Get-Thing.Tests.ps1", true},
+		{"src/*.ps1", "src/a.ps1", true},
+		{"src/*.ps1", "src/lib/a.ps1", false},
+		{"src/**/*.ps1", "src/lib/deep/a.ps1", true},
+		{"src/**/*.ps1", "src/a.ps1", true},
+		{"legacy/", "legacy/old.ps1", true},
+		{"legacy", "src/legacy", true},
+		{"bin", "cabinet.ps1", false},
+		{"step-[12].ps1", "step-2.ps1", true},
+		{"step-[!12].ps1", "step-2.ps1", false},
+		{"src/**", "src/lib/a.ps1", true},
+		{"src/a**.ps1", "src/a/b.ps1", false},
+		{`\#notes.ps1`, "#notes.ps1", true},
+	}
+	for _, c := range cases {
+		re, err := globRegexp(c.pattern)
+		if err != nil {
+			t.Fatalf("globRegexp(%q) failed: %v", c.pattern, err)
+		}
+		if got := re.MatchString(c.path); got != c.want {
+			t.Errorf("%q matching %q = %v, want %v", c.pattern, c.path, got, c.want)
+		}
+	}
+	if _, err := globRegexp("step-[12.ps1"); err == nil {
+		t.Error("Expected an unterminated character class to be rejected")
+	}
+}
+
+func TestParseProjectConfig(t *testing.T) {
+	config, problems := parseProjectConfig([]byte(`# yaml-language-server: $schema=../docs/pwsh-skills.schema.json
+include: ["**/*.ps1"]
+exclude: ["legacy/"]
+format: sarif
+failOn: warning
+rules:
+  BP002: off
+  CP001: error
+  PSAvoidUsingPositionalParameters: info
+windowsOnlyCmdlets: [Get-CimInstance]
+`))
+	if len(problems) > 0 {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+	if config.Format != formatSARIF || config.FailOn != severityWarning || len(config.WindowsOnlyCmdlets) != 1 {
+		t.Errorf("Unexpected configuration %+v", config)
+	}
+	findings := config.configure([]Finding{
+		{RuleID: "BP002", Severity: severityInfo},
+		{RuleID: "CP001", Severity: severityWarning},
+		{RuleID: "BP001", Severity: severityInfo},
+	})
+	if len(findings) != 2 || findings[0].RuleID != "CP001" || findings[0].Severity != severityError || findings[1].Severity != severityInfo {
+		t.Errorf("Expected BP002 off and CP001 as an error, got %+v", findings)
+	}
+	if !config.excludes("legacy/old.ps1") || config.excludes("src/new.ps1") {
+		t.Error("Unexpected exclusions")
+	}
+
+	_, problems = parseProjectConfig([]byte(`includes: ["*.ps1"]
+format: html
+rules:
+  SY001: off
+  XY123: warning
+  CP002: loud
+exclude: "legacy"
+windowsOnlyCmdlets: ["Get-Service; rm"]
+`))
+	var got []string
+	for _, problem := range problems {
+		got = append(got, fmt.Sprintf("%d", problem.Line))
+	}
+	if want := "1 2 4 5 6 7 8"; strings.Join(got, " ") != want {
+		t.Errorf("Expected problems on lines %s, got %v", want, problems)
+	}
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	repo := t.TempDir()
+	nested := filepath.Join(repo, "course", "step-1")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(nested)
+
+	if path, err := findProjectConfig(); err != nil || path != "" {
+		t.Errorf("Expected no configuration, got %q, %v", path, err)
+	}
+	config := filepath.Join(repo, ".pwsh-skills.yml")
+	if err := os.WriteFile(config, []byte("failOn: warning\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path, err := findProjectConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(path); resolved != config {
+		if want, _ := filepath.EvalSymlinks(config); resolved != want {
+			t.Errorf("Expected %s, got %s", config, path)
+		}
+	}
+}
+
+func TestProjectConfigMatchesSchema(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "docs", "pwsh-skills.schema.json"))
+	if err != nil {
+		t.Fatalf("Failed to read the schema: %v", err)
+	}
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Invalid schema: %v", err)
+	}
+
+	var settings, properties []string
+	configType := reflect.TypeOf(ProjectConfig{})
+	for i := 0; i < configType.NumField(); i++ {
+		if tag := configType.Field(i).Tag.Get("yaml"); tag != "" {
+			settings = append(settings, tag)
+		}
+	}
+	for property := range schema.Properties {
+		properties = append(properties, property)
+	}
+	sort.Strings(settings)
+	sort.Strings(properties)
+	if !reflect.DeepEqual(settings, properties) {
+		t.Errorf("Schema properties %v do not match the configuration settings %v", properties, settings)
+	}
+}
+
+func TestFindModuleFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, name := range []string{"Greeting/Greeting.psd1", "Greeting/Greeting.psm1", "Greeting/Greeting.Format.ps1xml", "script.PS1", "notes.txt", ".git/hook.ps1"} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := findPowerShellFiles()
+	want := []string{"Greeting/Greeting.Format.ps1xml", "Greeting/Greeting.psd1", "Greeting/Greeting.psm1", "script.PS1"}
+	for i := range want {
+		want[i] = filepath.FromSlash(want[i])
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected %v, got %v", want, files)
+	}
+	if checks := fileChecks(filepath.Join("Greeting", "Greeting.psm1")); containsString(checks, categoryModule) {
+		t.Errorf("A .psm1 with a manifest beside it should be checked through the manifest, got %v", checks)
+	}
+	if checks := fileChecks("Other.psm1"); !containsString(checks, categoryModule) {
+		t.Errorf("A .psm1 without a manifest should be imported, got %v", checks)
+	}
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"bin/", "bin", true, true},
+		{"bin/", "src/bin", true, true},
+		{"bin/", "bin", false, false},
+		{"bin/", "cabinet.ps1", false, false},
+		{"obj/", "robjects", true, false},
+		{"*.tmp.ps1", "deep/x.tmp.ps1", false, true},
+		{"/build.ps1", "build.ps1", false, true},
+		{"/build.ps1", "src/build.ps1", false, false},
+		{"gen/*", "gen/a.ps1", false, true},
+		{"gen/*", "src/gen/a.ps1", false, false},
+		{"**/gen/*.ps1", "src/gen/a.ps1", false, true},
+		{"src/**/a.ps1", "src/x/y/a.ps1", false, true},
+		{"src/**", "src/x/a.ps1", false, true},
+		{"Build.ps1", "build.ps1", false, false},
+		{`\#notes.ps1`, "#notes.ps1", false, true},
+	}
+	for _, c := range cases {
+		pattern, ok := compileIgnorePattern(c.pattern)
+		if !ok {
+			t.Fatalf("compileIgnorePattern(%q) failed", c.pattern)
+		}
+		got := pattern.re.MatchString(c.path) && (c.isDir || !pattern.dirOnly)
+		if got != c.want {
+			t.Errorf("%q matching %q = %v, want %v", c.pattern, c.path, got, c.want)
+		}
+	}
+	for _, line := range []string{"", "   ", "# comment", "step-[12.ps1"} {
+		if _, ok := compileIgnorePattern(line); ok {
+			t.Errorf("Expected %q to be no pattern", line)
+		}
+	}
+	if pattern, ok := compileIgnorePattern("!keep.ps1"); !ok || !pattern.negate || !pattern.re.MatchString("keep.ps1") {
+		t.Errorf("Expected a negated pattern, got %+v", pattern)
+	}
+}
+
+func TestFileDiscovery(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	files := map[string]string{
+		"cabinet.ps1":                 "",
+		"obj.ps1":                     "",
+		"robjects/a.ps1":              "",
+		"bin/b.ps1":                   "",
+		"src/obj/c.ps1":               "",
+		"node_modules/x/x.ps1":        "",
+		"gen/g.ps1":                   "",
+		"gen/keep.ps1":                "",
+		"x.tmp.ps1":                   "",
+		"course/s.ps1":                "",
+		"course/t.ps1":                "",
+		"shared/m.ps1":                "",
+		".gitignore":                  "gen/*\n!gen/keep.ps1\n",
+		".git/info/exclude":           "*.tmp.ps1\n",
+		"course/" + ignoreFileName:    "s.ps1\n",
+		".github/workflows/check.ps1": "",
+		"notes.txt":                   "",
+	}
+	for name, content := range files {
+		if err := writeFileWithDirs(name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outside := filepath.Join(t.TempDir(), "outside.ps1")
+	if err := os.WriteFile(outside, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"again.ps1":     "cabinet.ps1",
+		"out.ps1":       outside,
+		"broken.ps1":    "missing.ps1",
+		"course/shared": filepath.Join("..", "shared"),
+		"course/up":     "..",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.FromSlash(name)); err != nil {
+			t.Skipf("Cannot create symlinks: %v", err)
+		}
+	}
+
+	discovery := newFileDiscovery()
+	got, _ := discovery.scan(".")
+	want := []string{"cabinet.ps1", "course/t.ps1", "gen/keep.ps1", "obj.ps1", "robjects/a.ps1", "shared/m.ps1"}
+	for i := range want {
+		want[i] = filepath.FromSlash(want[i])
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	skipped := map[string]bool{}
+	for _, decision := range discovery.decisions {
+		if !decision.Included {
+			skipped[filepath.ToSlash(decision.Path)] = true
+		}
+	}
+	for _, path := range []string{".git", ".github", "again.ps1", "bin", "broken.ps1", "course/s.ps1", "course/shared", "course/up", "gen/g.ps1", "node_modules", "out.ps1", "src/obj", "x.tmp.ps1"} {
+		if !skipped[path] {
+			t.Errorf("Expected %s to be listed as skipped, got %+v", path, discovery.decisions)
+		}
+	}
+
+	changed := selectChangedFiles([]string{"cabinet.ps1", filepath.Join("gen", "g.ps1"), filepath.Join("node_modules", "x", "x.ps1"), filepath.Join("course", "s.ps1"), "out.ps1"})
+	if !reflect.DeepEqual(changed, []string{"cabinet.ps1"}) {
+		t.Errorf("Expected only cabinet.ps1 of the changed files, got %v", changed)
+	}
+}
+
+func TestValidateXML(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("valid.ps1xml", []byte("\ufeff<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<Types>\n  <Type><Name>System.String</Name></Type>\n</Types>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("broken.ps1xml", []byte("<Types>\n  <Type>\n</Types>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := FileResult{File: "valid.ps1xml", Findings: []Finding{}}
+	if !validateXML(io.Discard, &result) || !result.passed() {
+		t.Errorf("Expected valid.ps1xml to pass, got %+v", result)
+	}
+	result = FileResult{File: "broken.ps1xml", Findings: []Finding{}}
+	if validateXML(io.Discard, &result) || len(result.Findings) != 1 || result.Findings[0].RuleID != "SY002" || result.Findings[0].Line != 3 {
+		t.Errorf("Expected SY002 on line 3, got %+v", result.Findings)
+	}
+}
+
+func TestCheckManifest(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll("Greeting", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("Greeting", "Greeting.psm1"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := func(line int, key string, values ...string) ManifestEntry {
+		return ManifestEntry{Extent: Extent{Line: line, Column: 5}, Key: key, Values: values, Constant: true}
+	}
+	tree := &SyntaxTree{File: filepath.Join("Greeting", "Greeting.psd1"), Manifest: []ManifestEntry{
+		entry(2, "RootModule", "Greeting.psm1"),
+		entry(3, "moduleVersion", "1.0"),
+		entry(4, "GUID", "0c9b0a7e-6f0e-4d8c-9a53-3d0c3f6f5a11"),
+		entry(5, "PowerShellVersion", "7"),
+		entry(6, "FormatsToProcess", `Formats\Greeting.Format.ps1xml`),
+		entry(7, "NestedModules", "Microsoft.PowerShell.Utility"),
+		entry(8, "FunctionsToExport", "*"),
+		entry(9, "PrivateData.PSData.Prerelease", "beta.1"),
+	}}
+	if !isModuleManifest(tree) || isModuleManifest(&SyntaxTree{Manifest: []ManifestEntry{entry(1, "Rules")}}) {
+		t.Error("Expected only the module manifest to be recognised")
+	}
+
+	findings, err := checkManifest(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, finding := range findings {
+		got = append(got, fmt.Sprintf("%s:%d", finding.RuleID, finding.Line))
+	}
+	want := []string{"MM001:0", "MM001:0", "MM005:5", "MM005:9", "MM002:6"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if !blocksImport(findings) || blocksImport(findings[:2]) {
+		t.Error("Expected only missing files and invalid versions to block the import")
+	}
+}
+
+func TestModuleScripts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, name := range []string{"Greeting.psm1", "Public/Get-Farewell.ps1", "Private/Format-Name.ps1", "Private/Skip.ps1", "Private/Get-Name.Tests.ps1", "Build/Publish.ps1"} {
+		if err := writeFileWithDirs(name, []byte("\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(ignoreFileName, []byte("Skip.ps1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &SyntaxTree{File: "Greeting.psd1", Manifest: []ManifestEntry{
+		{Key: "RootModule", Values: []string{"Greeting.psm1"}, Constant: true},
+	}}
+
+	// Only the scripts the root module dot-sources belong to the module
+	dotSources := map[string][]DotSourceNode{
+		"Greeting.psm1": {{Path: "Public/Get-Farewell.ps1"}, {Path: "Public/Missing.ps1"}},
+	}
+	parse := func(files []string) (map[string]*SyntaxTree, error) {
+		trees := map[string]*SyntaxTree{}
+		for _, file := range files {
+			trees[file] = &SyntaxTree{File: file, DotSources: dotSources[filepath.ToSlash(file)]}
+		}
+		return trees, nil
+	}
+	files, _, root, err := moduleScripts(manifest, parse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Greeting.psm1", filepath.Join("Public", "Get-Farewell.ps1")}
+	if root != "Greeting.psm1" || !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected root Greeting.psm1 and scripts %v, got %q and %v", want, root, files)
+	}
+
+	// A path only known at run time adds the scripts validate would find, tests and ignored
+	// files left out
+	dotSources["Greeting.psm1"] = []DotSourceNode{{Path: ""}}
+	files, _, _, err = moduleScripts(manifest, parse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	want = []string{filepath.Join("Build", "Publish.ps1"), "Greeting.psm1", filepath.Join("Private", "Format-Name.ps1"), filepath.Join("Public", "Get-Farewell.ps1")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected scripts %v, got %v", want, files)
+	}
+}
+
+func TestCheckManifestExports(t *testing.T) {
+	if !isPowerShellAvailable() {
+		t.Skip("PowerShell is not installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"Greeting.psd1":           "@{\n    RootModule = 'Greeting.psm1'\n    ModuleVersion = '1.0.0'\n    GUID = '0c9b0a7e-6f0e-4d8c-9a53-3d0c3f6f5a11'\n    Author = 'Learner'\n    Description = 'Greets'\n    FunctionsToExport = @('Get-Greeting', 'Get-Farewell', 'Set-Greeting')\n}\n",
+		"Greeting.psm1":           ". $PSScriptRoot/Public/Get-Farewell.ps1\nfunction Get-Greeting { param() 'Hello' }\nfunction Format-Name { param() function Inner {} }\n",
+		"Public/Get-Farewell.ps1": "function Get-Farewell { param() 'Bye' }\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tree, err := parsePowerShellFile("Greeting.psd1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := checkManifest(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, finding := range findings {
+		got = append(got, finding.RuleID+" "+finding.Message)
+	}
+	want := []string{
+		"MM003 " + T("validate.manifest_export_undefined", "Set-Greeting"),
+		"MM004 " + T("validate.manifest_not_exported", "Format-Name", "Greeting.psm1"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk"
+	want := "--- a/x.ps1\n+++ b/x.ps1\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -8,3 +8,4 @@\n h\n i\n j\n+k\n\\ No newline at end of file\n"
+	if diff := unifiedDiff("x.ps1", before, after); diff != want {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+	if diff := unifiedDiff("x.ps1", before, before); diff != "" {
+		t.Errorf("Expected no diff for equal texts, got %q", diff)
+	}
+}
+
+func TestPortablePath(t *testing.T) {
+	cases := map[string]string{
+		`C:\Temp\out.txt`:                        "",
+		`c:\windows\temp`:                        "",
+		`C:\Users\ada\AppData\Local\Temp\x`:      `(Join-Path -Path ([System.IO.Path]::GetTempPath()) -ChildPath 'x')`,
+		`C:\Users\ada\Documents\It's\report.csv`: `(Join-Path -Path $HOME -ChildPath 'Documents/It''s/report.csv')`,
+		`C:\Users\ada\`:                          `$HOME`,
+		`C:\Users\Public\Documents`:              "",
+		`D:\Logs\app.log`:                        "",
+	}
+	for path, want := range cases {
+		got, ok := portablePath(path)
+		if ok != (want != "") || got != want {
+			t.Errorf("portablePath(%q) = %q, %v; want %q", path, got, ok, want)
+		}
+	}
+}
+
+func TestFixFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	source := "function Get-Report {\r\n" +
+		"    gci C:\\Users\\ada\\AppData\\Local\\Temp\\out.txt\r\n" +
+		"}\r\n" +
+		"function Set-Report {\r\n" +
+		"    param($Name)\r\n" +
+		"    # pwsh-skills-disable-next-line BP004\r\n" +
+		"    % { $_ }\n" +
+		"}\r\n"
+	data := append([]byte{}, bomUTF16LE...)
+	for _, unit := range utf16.Encode([]rune(source)) {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+	if err := os.WriteFile("report.ps1", data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tree := &SyntaxTree{
+		File: "report.ps1",
+		Functions: []FunctionNode{
+			{Extent: Extent{Line: 1, Column: 10, EndLine: 1, EndColumn: 20}, Name: "Get-Report", Body: Extent{Line: 1, Column: 21, EndLine: 3, EndColumn: 2}},
+			{Extent: Extent{Line: 4, Column: 10, EndLine: 4, EndColumn: 20}, Name: "Set-Report", HasParamBlock: true,
+				Body: Extent{Line: 4, Column: 21, EndLine: 8, EndColumn: 2}, ParamBlock: Extent{Line: 5, Column: 5, EndLine: 5, EndColumn: 17}},
+		},
+		Commands: []CommandNode{
+			{Extent: Extent{Line: 2, Column: 5, EndLine: 2, EndColumn: 8}, Name: "gci"},
+			{Extent: Extent{Line: 7, Column: 5, EndLine: 7, EndColumn: 6}, Name: "%"},
+		},
+		Tokens: []Token{
+			{Extent: Extent{Line: 2, Column: 9, EndLine: 2, EndColumn: 48}, Kind: "Generic", Text: `C:\Users\ada\AppData\Local\Temp\out.txt`, Value: `C:\Users\ada\AppData\Local\Temp\out.txt`},
+			{Extent: Extent{Line: 6, Column: 5, EndLine: 6, EndColumn: 42}, Kind: "Comment", Text: "# pwsh-skills-disable-next-line BP004"},
+		},
+	}
+
+	fix, err := fixFile("report.ps1", tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "function Get-Report {\r\n" +
+		"    [CmdletBinding()]\r\n" +
+		"    param()\r\n" +
+		"    Get-ChildItem (Join-Path -Path ([System.IO.Path]::GetTempPath()) -ChildPath 'out.txt')\r\n" +
+		"}\r\n" +
+		"function Set-Report {\r\n" +
+		"    [CmdletBinding()]\r\n" +
+		"    param($Name)\r\n" +
+		"    # pwsh-skills-disable-next-line BP004\r\n" +
+		"    % { $_ }\r\n" +
+		"}\r\n"
+	if fix.After != want {
+		t.Errorf("Unexpected fixed source:\n%s", fix.After)
+	}
+	if string(fix.Data) != string(encodeSource(want, encodingUTF16LE)) {
+		t.Error("Expected the fixed source to keep its UTF-16 encoding")
+	}
+	var rules []string
+	for _, description := range fix.Fixes {
+		rules = append(rules, description[strings.LastIndex(description, " ")+1:])
+	}
+	if len(fix.Fixes) != 5 || strings.Join(rules[:4], " ") != "(BP003) (BP004) (CP002) (BP001)" {
+		t.Errorf("Unexpected fixes %v", fix.Fixes)
+	}
+
+	if _, _, err := decodeSource([]byte{'$', 0xE9, '\n'}); err == nil {
+		t.Error("Expected text in a legacy code page to be left alone")
+	}
+}
+
+func TestFixEncoding(t *testing.T) {
+	t.Chdir(t.TempDir())
+	defer func(enabled bool) { analyzerEnabled = enabled }(analyzerEnabled)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("ascii.ps1", "'plain'\n")
+	write("accent.ps1", "'café'\n")
+	write("suppressed.ps1", "# pwsh-skills-disable-file PSUseBOMForUnicodeEncodedFile\n'café'\n")
+	suppressed := &SyntaxTree{File: "suppressed.ps1", Tokens: []Token{
+		{Extent: Extent{1, 1, 1, 57}, Kind: "Comment", Text: "# pwsh-skills-disable-file PSUseBOMForUnicodeEncodedFile"},
+	}}
+
+	// Only the encoding rule changes the encoding
+	analyzerEnabled = true
+	for _, tt := range []struct {
+		file string
+		tree *SyntaxTree
+		bom  bool
+	}{
+		{"ascii.ps1", nil, false},
+		{"accent.ps1", nil, true},
+		{"suppressed.ps1", suppressed, false},
+	} {
+		fix, err := fixFile(tt.file, tt.tree)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixes := 0
+		if tt.bom {
+			fixes = 1
+		}
+		if bom := strings.HasPrefix(string(fix.Data), string(bomUTF8)); bom != tt.bom || len(fix.Fixes) != fixes {
+			t.Errorf("%s: expected a byte order mark %v, got %v with fixes %v", tt.file, tt.bom, bom, fix.Fixes)
+		}
+	}
+
+	analyzerEnabled = false
+	if fix, err := fixFile("accent.ps1", nil); err != nil || len(fix.Fixes) != 0 || string(fix.Data) != "'café'\n" {
+		t.Errorf("Expected no encoding change without PSScriptAnalyzer, got %v, %v", fix, err)
+	}
+}
+
+func TestFixParamBlockAfterHelp(t *testing.T) {
+	cases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			"block help",
+			"function Get-Report {\n    <#\n    .SYNOPSIS\n    Reports.\n    #>\n    'report'\n}\n",
+			"function Get-Report {\n    <#\n    .SYNOPSIS\n    Reports.\n    #>\n    [CmdletBinding()]\n    param()\n    'report'\n}\n",
+		},
+		{
+			"line comment help",
+			"function Get-Report {\r\n    # .SYNOPSIS\r\n    # Reports.\r\n    'report'\r\n}\r\n",
+			"function Get-Report {\r\n    # .SYNOPSIS\r\n    # Reports.\r\n    [CmdletBinding()]\r\n    param()\r\n    'report'\r\n}\r\n",
+		},
+		{
+			"ordinary comment",
+			"function Get-Report {\n    <# not help #>\n    'report'\n}\n",
+			"function Get-Report {\n    [CmdletBinding()]\n    param()\n    <# not help #>\n    'report'\n}\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newSourceText(tt.source)
+			tree := &SyntaxTree{Functions: []FunctionNode{
+				{Extent: Extent{Line: 1, Column: 10, EndLine: 1, EndColumn: 20}, Name: "Get-Report", Body: Extent{Line: 1, Column: 21}},
+			}}
+			edits := fixParamBlock(source, tree, Finding{RuleID: "BP003", Line: 1, Column: 10})
+			if got := applyEdits(tt.source, edits); got != tt.want {
+				t.Errorf("Expected\n%q\ngot\n%q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFixLeavesUnsafeCodeAlone(t *testing.T) {
+	// A function reading $args would reject its arguments as an advanced function
+	source := newSourceText("function greet {\n    \"Hi $($args[0])\"\n}\nfunction say {\n    param($Word)\n    \"$Word $args\"\n}\n")
+	tree := &SyntaxTree{
+		File: "greet.ps1",
+		Functions: []FunctionNode{
+			{Extent: Extent{Line: 1, Column: 10, EndLine: 1, EndColumn: 15}, Name: "greet", UsesArgs: true, Body: Extent{Line: 1, Column: 16, EndLine: 3, EndColumn: 2}},
+			{Extent: Extent{Line: 4, Column: 10, EndLine: 4, EndColumn: 13}, Name: "say", UsesArgs: true, HasParamBlock: true,
+				Body: Extent{Line: 4, Column: 14, EndLine: 7, EndColumn: 2}, ParamBlock: Extent{Line: 5, Column: 5, EndLine: 5, EndColumn: 17}},
+		},
+	}
+	if edits := fixParamBlock(source, tree, Finding{RuleID: "BP003", Line: 1, Column: 10}); edits != nil {
+		t.Errorf("Expected no param() block for a function reading $args, got %+v", edits)
+	}
+	if edits := fixCmdletBinding(source, tree, Finding{RuleID: "BP001", Line: 4, Column: 10}); edits != nil {
+		t.Errorf("Expected no [CmdletBinding()] for a function reading $args, got %+v", edits)
+	}
+
+	// Data files and attribute arguments only take constants
+	path := `C:\Users\ada\Documents`
+	at := func(column int) ([]Token, Finding) {
+		extent := Extent{Line: 1, Column: column, EndLine: 1, EndColumn: column + len(path) + 2}
+		return []Token{{Extent: extent, Kind: "StringLiteral", Text: "'" + path + "'", Value: path}},
+			Finding{RuleID: "CP002", Line: 1, Column: column, EndLine: 1, EndColumn: extent.EndColumn}
+	}
+	tokens, finding := at(11)
+	data := &SyntaxTree{File: "Settings.psd1", Tokens: tokens}
+	if edits := fixWindowsPath(newSourceText("@{ Path = '"+path+"' }\n"), data, finding); edits != nil {
+		t.Errorf("Expected no Join-Path in a .psd1 file, got %+v", edits)
+	}
+	tokens, finding = at(8)
+	attribute := &SyntaxTree{File: "report.ps1", Tokens: tokens, Attributes: []Extent{{Line: 1, Column: 1, EndLine: 1, EndColumn: 34}}}
+	if edits := fixWindowsPath(newSourceText("[Alias('"+path+"')]\n"), attribute, finding); edits != nil {
+		t.Errorf("Expected no Join-Path in an attribute argument, got %+v", edits)
+	}
+	tokens, finding = at(6)
+	script := &SyntaxTree{File: "report.ps1", Tokens: tokens}
+	if edits := fixWindowsPath(newSourceText("$p = '"+path+"'\n"), script, finding); len(edits) != 1 {
+		t.Errorf("Expected the same path outside an attribute to be fixed, got %+v", edits)
+	}
+}
+
+func TestFindChangedAndStagedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "Learner")
+	t.Setenv("GIT_AUTHOR_EMAIL", "learner@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Learner")
+	t.Setenv("GIT_COMMITTER_EMAIL", "learner@example.com")
+	t.Chdir(t.TempDir())
+	git := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("kept.ps1", "'kept'\n")
+	write("edited.ps1", "'before'\n")
+	write("moved.ps1", "'moved'\n")
+	write("notes.txt", "notes\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Start")
+
+	write("edited.ps1", "'after'\n")
+	git("mv", "moved.ps1", "renamed.ps1")
+	write("new.psm1", "'staged'\n")
+	git("add", "new.psm1")
+	write("new.psm1", "'working tree'\n")
+	write("untracked.ps1", "'untracked'\n")
+	write("notes.txt", "more notes\n")
+
+	changed, err := findChangedFiles(changedBaseDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(changed)
+	if want := []string{"edited.ps1", "new.psm1", "renamed.ps1", "untracked.ps1"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Expected changed files %v, got %v", want, changed)
+	}
+
+	defer func() { stagedSources, stagedDir = map[string]string{}, "" }()
+	staged, err := findStagedFiles(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(staged)
+	if want := []string{"new.psm1", "renamed.ps1"}; !reflect.DeepEqual(staged, want) {
+		t.Errorf("Expected staged files %v, got %v", want, staged)
+	}
+	if content, err := os.ReadFile(sourceFile("new.psm1")); err != nil || string(content) != "'staged'\n" {
+		t.Errorf("Expected the staged content of new.psm1, got %q, %v", content, err)
+	}
+	if sourceFile("edited.ps1") != "edited.ps1" {
+		t.Error("Expected unstaged files to be read from the working tree")
+	}
+
+	if _, err := findChangedFiles("no-such-ref"); ExitCode(err) != exitUsage {
+		t.Errorf("Expected an unknown ref to be a usage error, got %v", err)
+	}
+}
+
+func TestFindCommittedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "Learner")
+	t.Setenv("GIT_AUTHOR_EMAIL", "learner@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Learner")
+	t.Setenv("GIT_COMMITTER_EMAIL", "learner@example.com")
+	t.Chdir(t.TempDir())
+	git := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("edited.ps1", "'first'\n")
+	write("kept.ps1", "'kept'\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Start")
+	write("edited.ps1", "'second'\n")
+	write("added.ps1", "'added'\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Change")
+
+	// Uncommitted and untracked changes are not pushed
+	write("edited.ps1", "'working tree'\n")
+	write("untracked.ps1", "'untracked'\n")
+
+	defer func() { stagedSources, stagedDir, stagedRev = map[string]string{}, "", "" }()
+	files, err := findCommittedFiles("HEAD~1..HEAD", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	if want := []string{"added.ps1", "edited.ps1"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected the files the commits change %v, got %v", want, files)
+	}
+	if content, err := os.ReadFile(sourceFile("edited.ps1")); err != nil || string(content) != "'second'\n" {
+		t.Errorf("Expected the committed content of edited.ps1, got %q, %v", content, err)
+	}
+
+	stagedSources = map[string]string{}
+	files, err = findCommittedFiles("HEAD~1", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"edited.ps1", "kept.ps1"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected every file of a single commit %v, got %v", want, files)
+	}
+	if content, err := os.ReadFile(sourceFile("edited.ps1")); err != nil || string(content) != "'first'\n" {
+		t.Errorf("Expected the content of edited.ps1 in the commit, got %q, %v", content, err)
+	}
+	if _, err := os.Stat(stageFile("added.ps1")); err == nil {
+		t.Error("Expected a file missing from the commit not to be written")
+	}
+}
+
+func TestStagedModuleChecks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "Learner")
+	t.Setenv("GIT_AUTHOR_EMAIL", "learner@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Learner")
+	t.Setenv("GIT_COMMITTER_EMAIL", "learner@example.com")
+	t.Chdir(t.TempDir())
+	git := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := writeFileWithDirs(name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The root module is only in the index, the formats file only in the working tree
+	git("init", "-q")
+	write("Greeting/Greeting.psd1", "@{}\n")
+	write("Greeting/Greeting.psm1", "function Get-Greeting { 'Hello' }\n")
+	write("Greeting/Build/Publish.ps1", "'publish'\n")
+	write("notes.txt", "notes\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Start")
+	write("Greeting/Greeting.psd1", "@{ RootModule = 'Greeting.psm1' }\n")
+	git("add", ".")
+	if err := os.Remove(filepath.Join("Greeting", "Greeting.psm1")); err != nil {
+		t.Fatal(err)
+	}
+	write("Greeting/Greeting.Format.ps1xml", "<Configuration />\n")
+
+	defer func() { stagedSources, stagedDir = map[string]string{}, "" }()
+	dir := t.TempDir()
+	staged, err := findStagedFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join("Greeting", "Greeting.psd1")}; !reflect.DeepEqual(staged, want) {
+		t.Fatalf("Expected staged files %v, got %v", want, staged)
+	}
+
+	entry := func(key, value string) ManifestEntry {
+		return ManifestEntry{Extent: Extent{Line: 1, Column: 4}, Key: key, Values: []string{value}, Constant: true}
+	}
+	tree := &SyntaxTree{File: staged[0], Manifest: []ManifestEntry{
+		entry("RootModule", "Greeting.psm1"),
+		entry("FormatsToProcess", "Greeting.Format.ps1xml"),
+		entry("FunctionsToExport", "*"),
+	}}
+	findings, err := checkManifest(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var missing []string
+	for _, finding := range findings {
+		if finding.RuleID == "MM002" {
+			missing = append(missing, finding.Message)
+		}
+	}
+	if want := []string{T("validate.manifest_file_missing", "FormatsToProcess", "Greeting.Format.ps1xml")}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("Expected only the unstaged formats file to be missing, got %v", missing)
+	}
+
+	// Only the files the module loads are written from the index
+	var exported []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			exported = append(exported, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(exported)
+	if want := []string{"Greeting/Greeting.psd1", "Greeting/Greeting.psm1"}; !reflect.DeepEqual(exported, want) {
+		t.Errorf("Expected the staged copies %v, got %v", want, exported)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "Learner")
+	t.Setenv("GIT_AUTHOR_EMAIL", "learner@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Learner")
+	t.Setenv("GIT_COMMITTER_EMAIL", "learner@example.com")
+	repo := t.TempDir()
+	t.Chdir(repo)
+	git := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+
+	if _, err := hooksDir(); ExitCode(err) != exitNotInCourse {
+		t.Errorf("Expected a directory outside a repository to be exit code %d, got %v", exitNotInCourse, err)
+	}
+
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "Start")
+	dir, err := hooksDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := printHookStatus(io.Discard); ExitCode(err) != exitFailure {
+		t.Errorf("Expected status to fail without hooks, got %v", err)
+	}
+
+	// An existing hook is kept and runs before validation
+	existing := "#!/bin/sh\necho chained > chained.txt\n"
+	if err := os.WriteFile(filepath.Join(dir, "pre-commit"), []byte(existing), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := installHooks(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if err := installHooks(io.Discard); err != nil {
+		t.Fatalf("Expected installing again to succeed: %v", err)
+	}
+	if state := hookState(dir, "pre-commit"); state != hookChained {
+		t.Errorf("Expected pre-commit to be %s, got %s", hookChained, state)
+	}
+	if state := hookState(dir, "pre-push"); state != hookInstalled {
+		t.Errorf("Expected pre-push to be %s, got %s", hookInstalled, state)
+	}
+	if err := printHookStatus(io.Discard); err != nil {
+		t.Errorf("Expected status to pass with both hooks, got %v", err)
+	}
+
+	// Validation that cannot find PowerShell does not block the commit
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "gh"), []byte("#!/bin/sh\nexit 3\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	hook := exec.Command("sh", filepath.Join(dir, "pre-commit"))
+	hook.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if output, err := hook.CombinedOutput(); err != nil {
+		t.Errorf("Expected the hook to pass when PowerShell is missing: %v\n%s", err, output)
+	}
+	if _, err := os.Stat("chained.txt"); err != nil {
+		t.Error("Expected the existing hook to run")
+	}
+
+	// pre-push validates what each pushed ref sends and skips deleted branches
+	log := filepath.Join(t.TempDir(), "gh.log")
+	if err := os.WriteFile(filepath.Join(bin, "gh"), []byte("#!/bin/sh\necho \"$*\" >> \"$GH_LOG\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-q", "--allow-empty", "-m", "Next")
+	revParse := func(rev string) string {
+		output, err := exec.Command("git", "rev-parse", rev).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	head, first, zero := revParse("HEAD"), revParse("HEAD~1"), strings.Repeat("0", 40)
+	hook = exec.Command("sh", filepath.Join(dir, "pre-push"), "origin", "https://example.com/course.git")
+	hook.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"), "GH_LOG="+log)
+	hook.Stdin = strings.NewReader("refs/heads/main " + head + " refs/heads/main " + first + "\n" +
+		"(delete) " + zero + " refs/heads/old " + first + "\n" +
+		"refs/heads/new " + head + " refs/heads/new " + zero + "\n")
+	if output, err := hook.CombinedOutput(); err != nil {
+		t.Errorf("Expected the pre-push hook to pass: %v\n%s", err, output)
+	}
+	content, _ := os.ReadFile(log)
+	if want := "pwsh-skills validate --commits=" + first + ".." + head + "\npwsh-skills validate --commits=" + head + "\n"; string(content) != want {
+		t.Errorf("Expected pre-push to run\n%s\ngot\n%s", want, content)
+	}
+
+	// Worktrees share the repository's hooks
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	git("worktree", "add", "-q", worktree)
+	t.Chdir(worktree)
+	if worktreeDir, err := hooksDir(); err != nil || hookState(worktreeDir, "pre-push") != hookInstalled {
+		t.Errorf("Expected the worktree to see the installed hooks in %s, got %v", worktreeDir, err)
+	}
+
+	t.Chdir(repo)
+	if err := uninstallHooks(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "pre-commit")); err != nil || string(content) != existing {
+		t.Errorf("Expected the existing hook to be restored, got %q, %v", content, err)
+	}
+	if state := hookState(dir, "pre-push"); state != hookMissing {
+		t.Errorf("Expected pre-push to be %s, got %s", hookMissing, state)
+	}
+
+	// core.hooksPath moves the hooks
+	git("config", "core.hooksPath", ".githooks")
+	if err := installHooks(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if !isManagedHook(filepath.Join(".githooks", "pre-push")) {
+		t.Error("Expected the hooks to be installed in core.hooksPath")
+	}
+	if err := uninstallHooks(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if state := hookState(dir, "pre-commit"); state != hookForeign {
+		t.Errorf("Expected the hook outside core.hooksPath to be left alone, got %s", state)
+	}
+}
+
+func TestAffectedFiles(t *testing.T) {
+	known := []string{
+		"script.ps1",
+		filepath.Join("MyModule", "MyModule.psd1"),
+		filepath.Join("MyModule", "MyModule.psm1"),
+		filepath.Join("MyModule", "Public", "Get-Thing.ps1"),
+		filepath.Join("Other", "Other.psd1"),
+	}
+	tests := []struct {
+		name    string
+		changed []string
+		want    []string
+	}{
+		{"script", []string{"script.ps1"}, []string{"script.ps1"}},
+		{"module script", []string{filepath.Join("MyModule", "Public", "Get-Thing.ps1")}, []string{
+			filepath.Join("MyModule", "MyModule.psd1"),
+			filepath.Join("MyModule", "MyModule.psm1"),
+			filepath.Join("MyModule", "Public", "Get-Thing.ps1"),
+		}},
+		{"manifest", []string{filepath.Join("Other", "Other.psd1")}, []string{filepath.Join("Other", "Other.psd1")}},
+		{"ignored", []string{"notes.txt", filepath.Join(".git", "hook.ps1"), filepath.Join("node_modules", "x.ps1")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := affectedFiles(tt.changed, known); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckWatchFlags(t *testing.T) {
+	defer func() { validateFix, validateFormat = false, formatText }()
+	validateFormat = formatText
+	if err := checkWatchFlags(); err != nil {
+		t.Errorf("Expected plain --watch to be accepted, got %v", err)
+	}
+	validateFix = true
+	if err := checkWatchFlags(); ExitCode(err) != exitUsage {
+		t.Errorf("Expected --watch --fix to be a usage error, got %v", err)
+	}
+	validateFix, validateFormat = false, formatSARIF
+	if err := checkWatchFlags(); ExitCode(err) != exitUsage {
+		t.Errorf("Expected --watch --format sarif to be a usage error, got %v", err)
+	}
+	defer func() { validatePowerShell = nil }()
+	validateFormat, validatePowerShell = formatText, []string{"pwsh"}
+	if err := checkWatchFlags(); err != nil {
+		t.Errorf("Expected --watch with one --powershell to be accepted, got %v", err)
+	}
+	validatePowerShell = []string{"pwsh", "pwsh-preview"}
+	if err := checkWatchFlags(); ExitCode(err) != exitUsage {
+		t.Errorf("Expected --watch with two --powershell values to be a usage error, got %v", err)
+	}
+}
+
+func TestWatchReloadsConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	defer func(config *ProjectConfig) { projectConfig = config }(projectConfig)
+	for path, want := range map[string]bool{
+		".pwsh-skills.yml": true, filepath.Join("sub", ".gitignore"): true, ignoreFileName: true, "script.ps1": false,
+	} {
+		if isDiscoverySetting(path) != want {
+			t.Errorf("Expected isDiscoverySetting(%q) to be %v", path, want)
+		}
+	}
+
+	session := &watchSession{}
+	if err := os.WriteFile(".pwsh-skills.yml", []byte("rules:\n  CP001: off\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	session.reloadConfig()
+	if session.configProblem != "" || projectConfig.path == "" {
+		t.Fatalf("Expected the new configuration to be applied, got %q", session.configProblem)
+	}
+	applied := projectConfig
+
+	if err := os.WriteFile(".pwsh-skills.yml", []byte("rules:\n  CP001: loud\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	session.reloadConfig()
+	if session.configProblem == "" || projectConfig != applied {
+		t.Errorf("Expected an invalid configuration to keep the previous one, got %q", session.configProblem)
+	}
+}
+
+func TestResolvePowerShellExecutables(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"pwsh-lts", filepath.Join("tools", "pwsh")} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	got, err := resolvePowerShellExecutables([]string{"pwsh-lts", "tools/pwsh", "pwsh-lts"}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "pwsh-lts"), filepath.Join(dir, "tools", "pwsh")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if _, err := resolvePowerShellExecutables([]string{"pwsh-preview"}, dir); ExitCode(err) != exitEnvironment {
+		t.Errorf("Expected a missing executable to be exit code %d, got %v", exitEnvironment, err)
+	}
+}
+
+func TestMatrixFindingsHonourSuppressions(t *testing.T) {
+	tree := &SyntaxTree{
+		File: "Greeting.psd1",
+		Tokens: []Token{
+			{Extent: Extent{1, 1, 1, 38}, Kind: "Comment", Text: "# pwsh-skills-disable-file MM007"},
+		},
+	}
+	findings := []Finding{
+		builtinRule("MM006").finding(tree.File, Extent{}, T("validate.module_import_failed", "broken")),
+		builtinRule("MM007").finding(tree.File, Extent{}, T("validate.module_import_warning", "unapproved verbs")),
+	}
+
+	got := matrixFindings(tree, findings)
+	if len(got) != 1 || got[0].RuleID != "MM006" {
+		t.Errorf("Expected the suppressed MM007 to be dropped, got %v", got)
+	}
+	if status := checkStatus(matrixFindings(tree, findings[1:])); status != checkPassed {
+		t.Errorf("Expected a module with only suppressed findings to pass, got %s", status)
+	}
+
+	defer func() { validateIgnoreSuppressions = false }()
+	validateIgnoreSuppressions = true
+	if got := matrixFindings(tree, findings); len(got) != 2 {
+		t.Errorf("Expected --ignore-suppressions to keep every finding, got %v", got)
+	}
+}
+
+func TestPrintCompatibilityMatrix(t *testing.T) {
+	matrix := &CompatibilityMatrix{
+		PowerShell: []PowerShellHost{{Name: "pwsh 7.2.24", Version: "7.2.24"}, {Name: "pwsh 7.4.6", Version: "7.4.6"}},
+		Results: []MatrixResult{
+			{File: "script.ps1", PowerShell: "pwsh 7.2.24", Status: checkFailed, Findings: []Finding{{RuleID: "SY001", Severity: severityError, Line: 2, Column: 9, Message: "Unexpected token '?'"}}},
+			{File: "script.ps1", PowerShell: "pwsh 7.4.6", Status: checkPassed},
+			{File: "Types.ps1xml", PowerShell: "pwsh 7.2.24", Status: checkSkipped},
+			{File: "Types.ps1xml", PowerShell: "pwsh 7.4.6", Status: checkSkipped},
+		},
+	}
+	var out strings.Builder
+	printCompatibilityMatrix(&out, matrix, []string{"script.ps1", "Types.ps1xml"})
+
+	for _, want := range []string{
+		"File          pwsh 7.2.24  pwsh 7.4.6",
+		"script.ps1    ❌           ✅",
+		"Types.ps1xml  ➖           ➖",
+		"❌ script.ps1 with pwsh 7.2.24:",
+		"2:9 Unexpected token '?' (SY001)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected the matrix to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if failed := matrix.failedFiles(); !reflect.DeepEqual(failed, []string{"script.ps1"}) {
+		t.Errorf("Expected script.ps1 to fail, got %v", failed)
+	}
+
+	var report strings.Builder
+	if err := writeJSON(&report, ValidationReport{Files: []FileResult{{File: "script.ps1", Valid: true}}, Matrix: matrix}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(report.String(), `"valid": false`) || !strings.Contains(report.String(), `"matrix"`) {
+		t.Errorf("Expected the matrix to fail the JSON report, got:\n%s", report.String())
+	}
+}
+
+func TestMinimumVersion(t *testing.T) {
+	at := func(line, column int) Extent { return Extent{Line: line, Column: column, EndLine: line, EndColumn: column + 1} }
+	tree := &SyntaxTree{
+		File: "script.ps1",
+		Features: []FeatureNode{
+			{Extent: at(4, 9), Kind: "NullConditionalMember"},
+			{Extent: at(2, 9), Kind: "Ternary"},
+		},
+		Commands: []CommandNode{
+			{Extent: at(1, 1), Name: "ConvertFrom-Json", Parameters: []string{"AsHash"}},
+			{Extent: at(3, 13), Name: "%", Parameters: []string{"parallel"}},
+			{Extent: at(5, 1), Name: "Invoke-RestMethod", Parameters: []string{"Skip"}},
+			{Extent: at(6, 1), Name: "Get-ChildItem", Parameters: []string{"Recurse"}},
+		},
+	}
+
+	uses := versionUses(tree)
+	var syntax []string
+	for _, use := range uses {
+		syntax = append(syntax, use.Syntax+" "+use.Version)
+	}
+	want := []string{"ConvertFrom-Json -AsHashtable 6.0", "a ? b : c 7.0", "ForEach-Object -Parallel 7.0", "?. 7.1"}
+	if !reflect.DeepEqual(syntax, want) {
+		t.Errorf("Expected uses %v, got %v", want, syntax)
+	}
+	if minimum := minimumVersion(tree); minimum.Version != "7.1" || minimum.Syntax != "?." || minimum.Line != 4 {
+		t.Errorf("Expected ?. on line 4 to need 7.1, got %+v", minimum)
+	}
+	if minimum := minimumVersion(&SyntaxTree{}); minimum.Version != baselineVersion || minimum.Syntax != "" {
+		t.Errorf("Expected a plain script to need %s, got %+v", baselineVersion, minimum)
+	}
+
+	rules := func(tree *SyntaxTree) []string {
+		var ids []string
+		for _, finding := range runRules(tree, categoryCrossPlatform) {
+			ids = append(ids, fmt.Sprintf("%s@%d", finding.RuleID, finding.Line))
+		}
+		return ids
+	}
+	if got := rules(tree); !reflect.DeepEqual(got, []string{"MV002@4"}) {
+		t.Errorf("Expected MV002 at the ?. without #Requires, got %v", got)
+	}
+	tree.RequiredVersion = "7.0"
+	if got := rules(tree); !reflect.DeepEqual(got, []string{"MV001@4"}) {
+		t.Errorf("Expected MV001 for the code newer than #Requires -Version 7.0, got %v", got)
+	}
+	tree.RequiredVersion = "7.1.0.0"
+	if got := rules(tree); got != nil {
+		t.Errorf("Expected no findings under #Requires -Version 7.1, got %v", got)
+	}
+
+	for _, c := range []struct {
+		a, b string
+		want int
+	}{{"7.0", "5.1", 1}, {"5.1", "7", -1}, {"7.0", "7", 0}, {"6.2", "6.10", -1}} {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareVersions(%s, %s) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+	if got := shortVersion("7.2.0.0"); got != "7.2" {
+		t.Errorf("Expected 7.2, got %s", got)
+	}
+}
+
+func TestParseVersionFeatures(t *testing.T) {
+	if !isPowerShellAvailable() {
+		t.Skip("PowerShell is not installed")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	script := "#Requires -Version 5.1\n$a = $b ? 1 : 2\n$c ??= $d ?? 3\nGet-Item . && Get-Item .\n1..3 | ForEach-Object -Parallel { $_ }\n"
+	if err := os.WriteFile("script.ps1", []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tree, err := parsePowerShellFile("script.ps1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Diagnostics) > 0 {
+		t.Skipf("This PowerShell cannot parse PowerShell 7 syntax: %v", tree.Diagnostics)
+	}
+
+	var kinds []string
+	for _, feature := range tree.Features {
+		kinds = append(kinds, feature.Kind)
+	}
+	sort.Strings(kinds)
+	if want := []string{"NullCoalescing", "NullCoalescingAssignment", "PipelineChainAnd", "Ternary"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Expected features %v, got %v", want, kinds)
+	}
+	if tree.RequiredVersion != "5.1" {
+		t.Errorf("Expected #Requires -Version 5.1, got %q", tree.RequiredVersion)
+	}
+	if minimum := minimumVersion(tree); minimum.Version != "7.0" || minimum.Line != 2 {
+		t.Errorf("Expected the ternary operator on line 2 to need 7.0, got %+v", minimum)
+	}
+}
@@ -0,0 +1,476 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// projectConfigFiles are the names of the project configuration file, in order of preference
+var projectConfigFiles = []string{".pwsh-skills.yml", ".pwsh-skills.yaml"}
+
+// ruleOff turns a rule off in the rules section of the configuration
+const ruleOff = "off"
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with the .pwsh-skills.yml project configuration",
+	Long: `Commands for the .pwsh-skills.yml file that configures validate for a repository.
+
+The file is looked up in the current directory and its parents, up to the root of
+the git repository.`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check .pwsh-skills.yml against its schema",
+	Long: `Check the project configuration for unknown settings, values of the wrong type,
+unknown rules or severities and invalid globs, reporting each problem with its line.
+
+Checks the .pwsh-skills.yml found from the current directory unless a file is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return validateProjectConfigFile(args)
+	},
+}
+
+// ProjectConfig is the .pwsh-skills.yml configuration of a repository. Globs are relative
+// to the directory containing the file.
+type ProjectConfig struct {
+	Include            []string          `yaml:"include"`
+	Exclude            []string          `yaml:"exclude"`
+	Format             string            `yaml:"format"`
+	FailOn             string            `yaml:"failOn"`
+	Rules              map[string]string `yaml:"rules"`
+	WindowsOnlyCmdlets []string          `yaml:"windowsOnlyCmdlets"`
+	PowerShell         []string          `yaml:"powershell"`
+
+	path     string
+	include  []*regexp.Regexp
+	exclude  []*regexp.Regexp
+	ruleKeys map[string]string
+}
+
+// ConfigProblem is a mistake in the configuration file at a 1-based line
+type ConfigProblem struct {
+	Line    int
+	Message string
+}
+
+func (p ConfigProblem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// projectConfig is the configuration in effect, empty until loadProjectConfig finds a file
+var projectConfig = &ProjectConfig{}
+
+// findProjectConfig returns the configuration file in the current directory or the closest
+// parent, stopping at the root of the git repository, or "" when there is none
+func findProjectConfig() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, name := range projectConfigFiles {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// loadProjectConfig finds and parses the configuration file and makes it the configuration
+// in effect. The problems are returned when the file is not valid.
+func loadProjectConfig() ([]ConfigProblem, error) {
+	projectConfig = &ProjectConfig{}
+	path, err := findProjectConfig()
+	if err != nil || path == "" {
+		return nil, err
+	}
+	config, problems, err := readProjectConfig(path)
+	if err != nil || len(problems) > 0 {
+		return problems, err
+	}
+	projectConfig = config
+	return nil, nil
+}
+
+// readProjectConfig parses and checks a configuration file
+func readProjectConfig(path string) (*ProjectConfig, []ConfigProblem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, problems := parseProjectConfig(data)
+	if config != nil {
+		config.path = path
+	}
+	return config, problems, nil
+}
+
+// parseProjectConfig checks a configuration against the schema in docs/pwsh-skills.schema.json
+// and decodes it. The configuration is nil when there are problems.
+func parseProjectConfig(data []byte) (*ProjectConfig, []ConfigProblem) {
+	config := &ProjectConfig{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return config, nil
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, []ConfigProblem{{Message: err.Error()}}
+	}
+	problems := checkProjectConfig(&root)
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	if err := root.Decode(config); err != nil {
+		return nil, []ConfigProblem{{Message: err.Error()}}
+	}
+
+	config.include = mustCompileGlobs(config.Include...)
+	config.exclude = mustCompileGlobs(config.Exclude...)
+	config.ruleKeys = map[string]string{}
+	for rule, setting := range config.Rules {
+		config.ruleKeys[strings.ToLower(rule)] = setting
+	}
+	return config, nil
+}
+
+// checkProjectConfig checks the document against the configuration schema
+func checkProjectConfig(root *yaml.Node) []ConfigProblem {
+	if len(root.Content) == 0 {
+		return nil
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return []ConfigProblem{{doc.Line, "the configuration must be a mapping of settings"}}
+	}
+
+	var problems []ConfigProblem
+	problem := func(node *yaml.Node, format string, args ...interface{}) {
+		problems = append(problems, ConfigProblem{node.Line, fmt.Sprintf(format, args...)})
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		switch key.Value {
+		case "include", "exclude":
+			for _, item := range stringList(value, key.Value, problem) {
+				if _, err := globRegexp(item.Value); err != nil {
+					problem(item, "invalid glob %q: %v", item.Value, err)
+				}
+			}
+		case "windowsOnlyCmdlets":
+			for _, item := range stringList(value, key.Value, problem) {
+				if !commandNamePattern.MatchString(item.Value) {
+					problem(item, "%q is not a command name", item.Value)
+				}
+			}
+		case "powershell":
+			for _, item := range stringList(value, key.Value, problem) {
+				if strings.TrimSpace(item.Value) == "" {
+					problem(item, "powershell must only contain executable names or paths")
+				}
+			}
+		case "format":
+			if value.Kind != yaml.ScalarNode || !validFormat(value.Value) {
+				problem(value, "format must be one of: %s", strings.Join(validateFormats, ", "))
+			}
+		case "failOn":
+			if value.Kind != yaml.ScalarNode || (value.Value != severityError && value.Value != severityWarning) {
+				problem(value, "failOn must be one of: %s", strings.Join(failOnSeverities, ", "))
+			}
+		case "rules":
+			checkRuleSettings(value, problem)
+		default:
+			problem(key, "unknown setting %q", key.Value)
+		}
+	}
+	return problems
+}
+
+// stringList checks that value is a list of strings and returns its items
+func stringList(value *yaml.Node, name string, problem func(*yaml.Node, string, ...interface{})) []*yaml.Node {
+	if value.Kind != yaml.SequenceNode {
+		problem(value, "%s must be a list", name)
+		return nil
+	}
+	var items []*yaml.Node
+	for _, item := range value.Content {
+		if item.Kind != yaml.ScalarNode || item.Tag != "!!str" {
+			problem(item, "%s must only contain strings", name)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// checkRuleSettings checks the rules section: rule IDs mapped to off or a severity
+func checkRuleSettings(value *yaml.Node, problem func(*yaml.Node, string, ...interface{})) {
+	if value.Kind != yaml.MappingNode {
+		problem(value, "rules must map rule IDs to %s", strings.Join(ruleSettings(), ", "))
+		return
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		rule, setting := value.Content[i], value.Content[i+1]
+		switch {
+		case isBuiltinRule(rule.Value) && builtinRule(strings.ToUpper(rule.Value)).Category == categorySyntax:
+			problem(rule, "syntax errors (%s) cannot be configured", strings.ToUpper(rule.Value))
+		case !isBuiltinRule(rule.Value) && !strings.HasPrefix(rule.Value, "PS"):
+			problem(rule, "unknown rule %q; use a built-in rule ID or a PSScriptAnalyzer rule name", rule.Value)
+		}
+		if setting.Kind != yaml.ScalarNode || !containsString(ruleSettings(), setting.Value) {
+			problem(setting, "rule %s must be set to one of: %s", rule.Value, strings.Join(ruleSettings(), ", "))
+		}
+	}
+}
+
+// ruleSettings are the values a rule can be set to
+func ruleSettings() []string {
+	return []string{ruleOff, severityError, severityWarning, severityInfo}
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// globRegexp compiles a glob from the configuration, ignoring case so it matches alike on
+// every platform. A pattern without a slash matches at any depth, and a directory pattern
+// also matches everything below it.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return compileGlob(pattern, globOptions{ignoreCase: true, below: true})
+}
+
+// globOptions tell compileGlob whether to ignore case and whether a pattern also matches
+// everything below the directories it matches
+type globOptions struct {
+	ignoreCase bool
+	below      bool
+}
+
+// compileGlob compiles a glob into a regular expression matching slash-separated paths, as
+// git matches its ignore patterns. * and ? do not cross directories; **/ at the start or
+// between slashes matches any directories and a trailing /** everything inside. A backslash
+// escapes the next character, and a character class is negated with !.
+func compileGlob(pattern string, options globOptions) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	if options.ignoreCase {
+		expr.WriteString("(?i)")
+	}
+	expr.WriteString("^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		rest := string(runes[i:])
+		atStart := i == 0 || runes[i-1] == '/'
+		switch {
+		case atStart && strings.HasPrefix(rest, "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case atStart && rest == "**":
+			expr.WriteString(".*")
+			i++
+		case runes[i] == '*':
+			expr.WriteString("[^/]*")
+		case runes[i] == '?':
+			expr.WriteString("[^/]")
+		case runes[i] == '\\' && i+1 < len(runes):
+			expr.WriteString(regexp.QuoteMeta(string(runes[i+1])))
+			i++
+		case runes[i] == '[':
+			end := strings.IndexRune(rest, ']')
+			if end < 2 {
+				return nil, errors.New("unterminated character class")
+			}
+			class := []rune(rest[1:end])
+			if class[0] == '!' {
+				class[0] = '^'
+			}
+			expr.WriteString("[" + strings.ReplaceAll(string(class), `\`, `\\`) + "]")
+			i += len([]rune(rest[:end]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	if options.below {
+		expr.WriteString("(/.*)?")
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// mustCompileGlobs compiles globs that are known to be valid
+func mustCompileGlobs(patterns ...string) []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := globRegexp(pattern)
+		if err != nil {
+			panic(err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// relativePath returns path relative to the configuration file's directory, with slashes
+func (c *ProjectConfig) relativePath(path string) string {
+	if c.path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(filepath.Dir(c.path), abs); err == nil {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// excludes reports whether a file or directory is excluded from validation
+func (c *ProjectConfig) excludes(path string) bool {
+	rel := c.relativePath(path)
+	for _, re := range c.exclude {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// includes reports whether a PowerShell file is validated: every file is unless include
+// globs are configured, in which case the file must match one
+func (c *ProjectConfig) includes(path string) bool {
+	if len(c.include) == 0 {
+		return true
+	}
+	rel := c.relativePath(path)
+	for _, re := range c.include {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// configure drops the findings of rules turned off and applies configured severities
+func (c *ProjectConfig) configure(findings []Finding) []Finding {
+	if len(c.ruleKeys) == 0 {
+		return findings
+	}
+	configured := []Finding{}
+	for _, finding := range findings {
+		switch setting := c.ruleKeys[strings.ToLower(finding.RuleID)]; setting {
+		case "":
+		case ruleOff:
+			continue
+		default:
+			finding.Severity = setting
+		}
+		configured = append(configured, finding)
+	}
+	return configured
+}
+
+// useProjectConfig loads the configuration for a command, printing its problems and
+// returning a usage error when it is not valid
+func useProjectConfig() error {
+	problems, err := loadProjectConfig()
+	if err != nil {
+		fmt.Println(T("config.read_error", err))
+		return reported(exitError(exitUsage, err))
+	}
+	if len(problems) > 0 {
+		path, _ := findProjectConfig()
+		printConfigProblems(path, problems)
+		return reportedError(exitUsage, "%s is not valid", path)
+	}
+	return nil
+}
+
+// validateProjectConfigFile checks the given configuration file, or the one found from the
+// current directory
+func validateProjectConfigFile(args []string) error {
+	fmt.Println(T("config.title"))
+	fmt.Println("=============================================")
+
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		found, err := findProjectConfig()
+		if err != nil {
+			return err
+		}
+		if found == "" {
+			fmt.Println(T("config.not_found", projectConfigFiles[0]))
+			return reportedError(exitFailure, "no %s found", projectConfigFiles[0])
+		}
+		path = found
+	}
+
+	config, problems, err := readProjectConfig(path)
+	if err != nil {
+		fmt.Println(T("config.read_error", err))
+		return reported(exitError(exitUsage, err))
+	}
+	if len(problems) > 0 {
+		printConfigProblems(path, problems)
+		return reportedError(exitFailure, "%s is not valid", path)
+	}
+
+	fmt.Println(T("config.valid", path))
+	if len(config.Rules) > 0 {
+		rules := make([]string, 0, len(config.Rules))
+		for rule, setting := range config.Rules {
+			rules = append(rules, rule+": "+setting)
+		}
+		sort.Strings(rules)
+		fmt.Println("   " + T("config.rules", strings.Join(rules, ", ")))
+	}
+	return nil
+}
+
+// printConfigProblems lists the problems of a configuration file
+func printConfigProblems(path string, problems []ConfigProblem) {
+	fmt.Println(T("config.invalid", path, len(problems)))
+	for _, problem := range problems {
+		fmt.Printf("   • %s\n", problem)
+	}
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffCells bounds the memory the line diff uses; larger changes are shown as the whole
+// changed region removed and added again
+const maxDiffCells = 16 << 20
+
+// diffLine is a line of a diff: ' ' for an unchanged line, '-' for a removed one and '+' for
+// an added one. Text keeps the line ending, so changed line endings show as changed lines.
+type diffLine struct {
+	Kind byte
+	Text string
+}
+
+// unifiedDiff returns the changes from before to after as a unified diff, or "" when the
+// texts are equal
+func unifiedDiff(name, before, after string) string {
+	if before == after {
+		return ""
+	}
+	lines := diffLines(strings.SplitAfter(before, "\n"), strings.SplitAfter(after, "\n"))
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- a/%s\n+++ b/%s\n", name, name)
+	for start := 0; start < len(lines); {
+		// Find the next change and extend the hunk while changes are close together
+		first := start
+		for first < len(lines) && lines[first].Kind == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		last := first
+		for i := first; i < len(lines); i++ {
+			if lines[i].Kind != ' ' {
+				last = i
+			} else if i-last > 2*diffContext {
+				break
+			}
+		}
+		from := max(first-diffContext, start)
+		to := min(last+diffContext+1, len(lines))
+		writeHunk(&diff, lines, from, to)
+		start = to
+	}
+	return diff.String()
+}
+
+// writeHunk writes lines[from:to] as a hunk with its header
+func writeHunk(diff *strings.Builder, lines []diffLine, from, to int) {
+	oldStart, newStart := 1, 1
+	for _, line := range lines[:from] {
+		if line.Kind != '+' {
+			oldStart++
+		}
+		if line.Kind != '-' {
+			newStart++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, line := range lines[from:to] {
+		if line.Kind != '+' {
+			oldCount++
+		}
+		if line.Kind != '-' {
+			newCount++
+		}
+	}
+	// An empty side starts at the line before the hunk
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(diff, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, line := range lines[from:to] {
+		diff.WriteByte(line.Kind)
+		diff.WriteString(line.Text)
+		if !strings.HasSuffix(line.Text, "\n") {
+			diff.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// diffLines aligns two lists of lines by their longest common subsequence
+func diffLines(a, b []string) []diffLine {
+	// SplitAfter leaves an empty last element after a final newline
+	if len(a) > 0 && a[len(a)-1] == "" {
+		a = a[:len(a)-1]
+	}
+	if len(b) > 0 && b[len(b)-1] == "" {
+		b = b[:len(b)-1]
+	}
+
+	var lines []diffLine
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		lines = append(lines, diffLine{' ', a[prefix]})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	lines = append(lines, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	return lines
+}
+
+// diffMiddle diffs the lines between the common prefix and suffix
+func diffMiddle(a, b []string) []diffLine {
+	var lines []diffLine
+	n, m := len(a), len(b)
+	if n*m > maxDiffCells {
+		for _, line := range a {
+			lines = append(lines, diffLine{'-', line})
+		}
+		for _, line := range b {
+			lines = append(lines, diffLine{'+', line})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case j == m || i < n && lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ignoreFileName is the tool's own ignore file. It has the .gitignore syntax and leaves out
+// of validation files that git should still track.
+const ignoreFileName = ".pwsh-skillsignore"
+
+// builtinIgnores are the directories never searched for PowerShell files: dependencies and
+// build output. Files of the same name are still found.
+var builtinIgnores = []string{"node_modules/", "bin/", "obj/"}
+
+// ignorePattern is one line of an ignore file, matched against paths relative to base, the
+// directory of the file. Source names the file and line, or "built-in".
+type ignorePattern struct {
+	text    string
+	source  string
+	base    string
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// FileDecision records whether discovery included a PowerShell file, or why it skipped a
+// file or directory
+type FileDecision struct {
+	Path     string
+	Dir      bool
+	Included bool
+	Reason   string
+}
+
+// fileDiscovery finds the PowerShell files to validate. It honours the .gitignore and
+// .pwsh-skillsignore files from the repository root down, .git/info/exclude, the built-in
+// ignores and the project configuration, and follows symlinks only within the repository.
+type fileDiscovery struct {
+	cwd       string
+	top       string
+	realTop   string
+	global    []ignorePattern
+	patterns  map[string][]ignorePattern
+	searched  map[string]string
+	found     map[string]string
+	links     []string
+	decisions []FileDecision
+}
+
+// newFileDiscovery prepares discovery from the current directory, within the git
+// repository around it or the directory itself outside one
+func newFileDiscovery() *fileDiscovery {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "."
+	}
+	d := &fileDiscovery{
+		cwd:      cwd,
+		top:      repositoryRoot(cwd),
+		patterns: map[string][]ignorePattern{},
+		searched: map[string]string{},
+		found:    map[string]string{},
+	}
+	d.realTop = d.top
+	if real, err := filepath.EvalSymlinks(d.top); err == nil {
+		d.realTop = real
+	}
+	for _, text := range builtinIgnores {
+		pattern, _ := compileIgnorePattern(text)
+		pattern.source, pattern.base = T("validate.ignore_builtin"), d.top
+		d.global = append(d.global, pattern)
+	}
+	if path := gitExcludeFile(d.top); path != "" {
+		d.global = append(d.global, d.readIgnoreFile(path, d.top)...)
+	}
+	return d
+}
+
+// repositoryRoot returns the closest directory from dir up that holds .git, or dir when
+// there is none
+func repositoryRoot(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// gitExcludeFile returns the info/exclude file of the repository at top, or "" outside a
+// repository. The .git of a worktree is a file pointing elsewhere, so git is asked then.
+func gitExcludeFile(top string) string {
+	info, err := os.Stat(filepath.Join(top, ".git"))
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return filepath.Join(top, ".git", "info", "exclude")
+	}
+	output, err := runGit("-C", top, "rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(top, path)
+	}
+	return path
+}
+
+// compileIgnorePattern parses a line of an ignore file, returning false for blank lines,
+// comments and invalid patterns. As in git, a pattern with a slash before its end is
+// anchored to the ignore file's directory, any other matches a name at any depth, and a
+// trailing slash matches directories only.
+func compileIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	pattern := ignorePattern{text: line}
+	if strings.HasPrefix(line, "!") {
+		pattern.negate, line = true, line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly, line = true, strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		line = "**/" + line
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+	re, err := compileGlob(line, globOptions{})
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	pattern.re = re
+	return pattern, true
+}
+
+// readIgnoreFile returns the patterns of an ignore file, relative to base. A missing file
+// has none.
+func (d *fileDiscovery) readIgnoreFile(path, base string) []ignorePattern {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	name := path
+	if rel, err := filepath.Rel(d.cwd, path); err == nil {
+		name = rel
+	}
+	var patterns []ignorePattern
+	for i, line := range strings.Split(string(content), "\n") {
+		if pattern, ok := compileIgnorePattern(line); ok {
+			pattern.source, pattern.base = name+":"+strconv.Itoa(i+1), base
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// patternsIn returns the patterns of the .gitignore and .pwsh-skillsignore files of an
+// absolute directory, the latter taking precedence
+func (d *fileDiscovery) patternsIn(dir string) []ignorePattern {
+	patterns, ok := d.patterns[dir]
+	if !ok {
+		patterns = append(d.readIgnoreFile(filepath.Join(dir, ".gitignore"), dir), d.readIgnoreFile(filepath.Join(dir, ignoreFileName), dir)...)
+		d.patterns[dir] = patterns
+	}
+	return patterns
+}
+
+// ignoredBy returns the last pattern matching a path, which decides whether it is ignored:
+// built-in ignores and .git/info/exclude come first, then the ignore files from the
+// repository root down to the path's directory. It returns nil when none matches.
+func (d *fileDiscovery) ignoredBy(path string, isDir bool) *ignorePattern {
+	abs := filepath.Join(d.cwd, path)
+	lists := [][]ignorePattern{d.global}
+	if dir := filepath.Dir(abs); isWithin(d.top, dir) {
+		rel, _ := filepath.Rel(d.top, dir)
+		dir = d.top
+		lists = append(lists, d.patternsIn(dir))
+		if rel != "." {
+			for _, part := range strings.Split(rel, string(filepath.Separator)) {
+				dir = filepath.Join(dir, part)
+				lists = append(lists, d.patternsIn(dir))
+			}
+		}
+	}
+
+	var match *ignorePattern
+	for _, list := range lists {
+		for i := range list {
+			pattern := &list[i]
+			if pattern.dirOnly && !isDir {
+				continue
+			}
+			if rel, err := filepath.Rel(pattern.base, abs); err == nil && pattern.re.MatchString(filepath.ToSlash(rel)) {
+				match = pattern
+			}
+		}
+	}
+	return match
+}
+
+// skipReason returns why a file or directory is skipped, ignoring the directories above it,
+// or "" when it is not
+func (d *fileDiscovery) skipReason(path string, isDir bool) string {
+	if name := filepath.Base(path); strings.HasPrefix(name, ".") && name != "." && name != ".." {
+		return T("validate.skip_hidden")
+	}
+	if pattern := d.ignoredBy(path, isDir); pattern != nil && !pattern.negate {
+		return T("validate.skip_ignored", pattern.text, pattern.source)
+	}
+	if projectConfig.excludes(path) {
+		return T("validate.skip_excluded", filepath.Base(projectConfig.path))
+	}
+	return ""
+}
+
+// pathSkipReason returns why a path named by git rather than found by searching is skipped,
+// checking the directories above it too, or "" when it is not
+func (d *fileDiscovery) pathSkipReason(path string, isDir bool) string {
+	parts := strings.Split(filepath.Clean(path), string(filepath.Separator))
+	for i := range parts {
+		if reason := d.skipReason(filepath.Join(parts[:i+1]...), isDir || i < len(parts)-1); reason != "" {
+			return reason
+		}
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if _, _, reason := d.linkTarget(path); reason != "" {
+			return reason
+		}
+	}
+	if !isDir && !projectConfig.includes(path) {
+		return T("validate.skip_not_included", filepath.Base(projectConfig.path))
+	}
+	return ""
+}
+
+// linkTarget resolves a symlink, refusing one that is broken or leads outside the repository.
+// The target of a refused link is still returned when it exists.
+func (d *fileDiscovery) linkTarget(path string) (string, os.FileInfo, string) {
+	real, err := filepath.EvalSymlinks(filepath.Join(d.cwd, path))
+	if err != nil {
+		return "", nil, T("validate.skip_broken_link")
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		return "", nil, T("validate.skip_broken_link")
+	}
+	if !isWithin(d.realTop, real) {
+		return real, info, T("validate.skip_outside_link", real)
+	}
+	return real, info, ""
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// decide records a decision about a path
+func (d *fileDiscovery) decide(path string, isDir, included bool, reason string) {
+	d.decisions = append(d.decisions, FileDecision{Path: path, Dir: isDir, Included: included, Reason: reason})
+}
+
+// scan returns the PowerShell files below root and the directories searched for them.
+// Symlinks are followed once everything else is searched, so a file or directory reached
+// both directly and through a link is found under its own path. A link to root or a
+// directory above it would search everything again and is refused.
+func (d *fileDiscovery) scan(root string) (files, dirs []string) {
+	rootReal, err := filepath.EvalSymlinks(filepath.Join(d.cwd, root))
+	if err != nil {
+		return nil, nil
+	}
+	d.searched[rootReal] = root
+	files, dirs = d.scanDir(root, rootReal, files, append(dirs, root))
+
+	for len(d.links) > 0 {
+		path := d.links[0]
+		d.links = d.links[1:]
+		real, info, reason := d.linkTarget(path)
+		switch {
+		case reason != "":
+			isDir := info != nil && info.IsDir()
+			if isDir || isPowerShellFile(path) {
+				d.decide(path, isDir, false, reason)
+			}
+		case info.IsDir() && isWithin(real, rootReal):
+			d.decide(path, true, false, T("validate.skip_link_loop", real))
+		case info.IsDir():
+			if first, ok := d.searched[real]; ok {
+				d.decide(path, true, false, T("validate.skip_searched", first))
+				continue
+			}
+			d.searched[real] = path
+			dirs = append(dirs, path)
+			files, dirs = d.scanDir(path, real, files, dirs)
+		case isPowerShellFile(path):
+			files = d.addFile(path, real, files)
+		}
+	}
+	return files, dirs
+}
+
+// scanDir searches a directory whose real path is real, leaving the symlinks in it for
+// scan to follow
+func (d *fileDiscovery) scanDir(dir, real string, files, dirs []string) ([]string, []string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return files, dirs
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		isDir := entry.IsDir()
+		if !isDir && !isPowerShellFile(entry.Name()) && entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		if reason := d.skipReason(path, isDir); reason != "" {
+			d.decide(path, isDir, false, reason)
+			continue
+		}
+		switch {
+		case entry.Type()&os.ModeSymlink != 0:
+			d.links = append(d.links, path)
+		case isDir:
+			d.searched[filepath.Join(real, entry.Name())] = path
+			dirs = append(dirs, path)
+			files, dirs = d.scanDir(path, filepath.Join(real, entry.Name()), files, dirs)
+		default:
+			files = d.addFile(path, filepath.Join(real, entry.Name()), files)
+		}
+	}
+	return files, dirs
+}
+
+// addFile adds a PowerShell file found at path unless the include globs leave it out or it
+// was already found under another path
+func (d *fileDiscovery) addFile(path, real string, files []string) []string {
+	if !projectConfig.includes(path) {
+		d.decide(path, false, false, T("validate.skip_not_included", filepath.Base(projectConfig.path)))
+		return files
+	}
+	if first, ok := d.found[real]; ok {
+		d.decide(path, false, false, T("validate.skip_duplicate", first))
+		return files
+	}
+	d.found[real] = path
+	reason := ""
+	if pattern := d.ignoredBy(path, false); pattern != nil && pattern.negate {
+		reason = T("validate.reincluded", pattern.text, pattern.source)
+	}
+	d.decide(path, false, true, reason)
+	return append(files, path)
+}
+
+// printFileDecisions lists the PowerShell files validate would check and the files and
+// directories it skips, with the reason for each
+func printFileDecisions(out io.Writer, decisions []FileDecision) {
+	fmt.Fprintln(out, T("validate.files_title"))
+	fmt.Fprintln(out, "=============================================")
+	sorted := append([]FileDecision{}, decisions...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	included := 0
+	for _, decision := range sorted {
+		name := decision.Path
+		if decision.Dir {
+			name += string(filepath.Separator)
+		}
+		switch {
+		case decision.Included:
+			included++
+			if decision.Reason != "" {
+				fmt.Fprintf(out, "✅ %s (%s)\n", name, decision.Reason)
+			} else {
+				fmt.Fprintln(out, "✅ "+name)
+			}
+		default:
+			fmt.Fprintf(out, "➖ %s — %s\n", name, decision.Reason)
+		}
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, T("validate.files_summary", included, len(sorted)-included))
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the CLI, documented in the README under "Exit Codes"
+const (
+	exitFailure     = 1 // validation findings, failing tests or a command that could not do its job
+	exitUsage       = 2 // unknown commands, flags or arguments
+	exitEnvironment = 3 // pwsh, gh, git or a required PowerShell module is missing or broken
+	exitNotInCourse = 4 // the command needs a course repository and was run outside one
+)
+
+// ExitError is an error that ends the CLI with a specific exit code. Reported errors have
+// already been explained by the command's own output, so main does not print them again.
+type ExitError struct {
+	Code     int
+	Err      error
+	Reported bool
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// exitError gives err the exit code code
+func exitError(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+// reportedError is a failure the command has already printed, ending the CLI with code
+func reportedError(code int, format string, args ...interface{}) error {
+	return &ExitError{Code: code, Err: fmt.Errorf(format, args...), Reported: true}
+}
+
+// reported marks err as already explained to the user, keeping its exit code
+func reported(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ExitError{Code: exitCodeOf(err), Err: err, Reported: true}
+}
+
+// exitCodeOf returns the exit code carried by err, or exitFailure when it carries none
+func exitCodeOf(err error) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	if errors.Is(err, errPowerShellNotFound) {
+		return exitEnvironment
+	}
+	return exitFailure
+}
+
+// ExitCode returns the process exit code for an error returned by Execute. Errors from a
+// command's RunE carry their code; any other error comes from cobra rejecting the command
+// line, which is a usage error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return exitUsage
+}
+
+// IsReported reports whether the command already printed the error returned by Execute
+func IsReported(err error) bool {
+	var exitErr *ExitError
+	return errors.As(err, &exitErr) && exitErr.Reported
+}
+
+// withExitCodes wraps the RunE of cmd and its subcommands so every error a command returns
+// carries an exit code, and so failures no longer print the usage text meant for mistakes
+// on the command line
+func withExitCodes(cmd *cobra.Command) {
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			err := run(cmd, args)
+			var exitErr *ExitError
+			if err != nil && !errors.As(err, &exitErr) {
+				err = exitError(exitCodeOf(err), err)
+			}
+			return err
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		withExitCodes(sub)
+	}
+}
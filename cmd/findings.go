@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Severities of a Finding
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityInfo    = "info"
+)
+
+// Categories of a Finding
+const (
+	categorySyntax        = "syntax"
+	categoryCrossPlatform = "cross-platform"
+	categoryBestPractices = "best-practices"
+	categoryModule        = "module"
+)
+
+// Finding is an issue reported by a validation check, either built in or from PSScriptAnalyzer.
+// Line and column are 1-based; zero means the finding has no location within the file.
+type Finding struct {
+	RuleID    string `json:"ruleId"`
+	Severity  string `json:"severity"`
+	Category  string `json:"category"`
+	File      string `json:"file"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+	Message   string `json:"message"`
+	Source    string `json:"source"`
+}
+
+// Sources of a Finding
+const (
+	sourceBuiltin  = "builtin"
+	sourceAnalyzer = "PSScriptAnalyzer"
+)
+
+// severityIcons are shown in front of each finding
+var severityIcons = map[string]string{
+	severityError:   "❌",
+	severityWarning: "⚠️ ",
+	severityInfo:    "ℹ️ ",
+}
+
+// sortFindings orders findings by line and column, keeping findings without a location last
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if (a.Line == 0) != (b.Line == 0) {
+			return a.Line != 0
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.RuleID < b.RuleID
+	})
+}
+
+// printFinding prints a finding as a bullet with its severity, location and rule
+func printFinding(out io.Writer, finding Finding) {
+	location := ""
+	if finding.Line > 0 {
+		location = fmt.Sprintf("%d:%d ", finding.Line, finding.Column)
+	}
+	fmt.Fprintf(out, "     • %s %s%s (%s)\n", severityIcons[finding.Severity], location, finding.Message, finding.RuleID)
+}
@@ -0,0 +1,532 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Byte order marks of the encodings PowerShell reads scripts in
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Encodings of a source file, as shown when --fix changes it
+const (
+	encodingUTF8    = "UTF-8"
+	encodingUTF8BOM = "UTF-8 with BOM"
+	encodingUTF16LE = "UTF-16 LE"
+	encodingUTF16BE = "UTF-16 BE"
+)
+
+// bomRule is the PSScriptAnalyzer rule asking for a byte order mark in a UTF-8 file with
+// non-ASCII characters, which Windows PowerShell would otherwise read in the legacy code page
+const bomRule = "PSUseBOMForUnicodeEncodedFile"
+
+// helpKeyword matches a keyword of comment-based help, such as .SYNOPSIS
+var helpKeyword = regexp.MustCompile(`(?im)^[\s#]*\.(SYNOPSIS|DESCRIPTION|PARAMETER|EXAMPLE|INPUTS|OUTPUTS|NOTES|LINK|COMPONENT|ROLE|FUNCTIONALITY|FORWARDHELPTARGETNAME|FORWARDHELPCATEGORY|REMOTEHELPRUNSPACE|EXTERNALHELP)\b`)
+
+// fixableExtensions are the files --fix rewrites; .ps1xml files are XML and keep the
+// encoding their declaration names
+var fixableExtensions = []string{".ps1", ".psm1", ".psd1"}
+
+// TextEdit replaces the text between two byte offsets of a decoded source file
+type TextEdit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// FileFix is what --fix changes in a file: the decoded text before and after, the encoded
+// result and a description of every fix
+type FileFix struct {
+	File   string
+	Before string
+	After  string
+	Data   []byte
+	Fixes  []string
+}
+
+// sourceText is a decoded source file with the offset of each line, to turn the parser's
+// 1-based lines and columns, which count UTF-16 code units, into byte offsets
+type sourceText struct {
+	text    string
+	lines   []int
+	newline string
+}
+
+// newSourceText indexes the lines of text. Edits that add lines use the file's first line
+// ending, which --fix also makes every other line use.
+func newSourceText(text string) *sourceText {
+	source := &sourceText{text: text, lines: []int{0}, newline: "\n"}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			source.lines = append(source.lines, i+1)
+		}
+	}
+	if i := strings.Index(text, "\n"); i > 0 && text[i-1] == '\r' {
+		source.newline = "\r\n"
+	}
+	return source
+}
+
+// offset returns the byte offset of a line and column, or -1 when it is outside the text
+func (s *sourceText) offset(line, column int) int {
+	if line < 1 || line > len(s.lines) || column < 1 {
+		return -1
+	}
+	offset := s.lines[line-1]
+	for units := column - 1; units > 0; {
+		if offset >= len(s.text) || s.text[offset] == '\n' {
+			return -1
+		}
+		r, size := utf8.DecodeRuneInString(s.text[offset:])
+		units -= utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+// lineRest returns the text from offset to the end of its line, without the line ending
+func (s *sourceText) lineRest(offset int) string {
+	rest := s.text[offset:]
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i]
+	}
+	return strings.TrimSuffix(rest, "\r")
+}
+
+// helpEnd returns the offset just after the comment-based help that starts the text at
+// offset, blank lines aside, or -1 when the text does not start with help ending its line.
+// Help is a <# #> block or consecutive line comments naming a help keyword.
+func (s *sourceText) helpEnd(offset int) int {
+	start := len(s.text) - len(strings.TrimLeft(s.text[offset:], " \t\r\n"))
+	rest := s.text[start:]
+	end := -1
+	if strings.HasPrefix(rest, "<#") {
+		if i := strings.Index(rest, "#>"); i >= 0 {
+			end = i + 2
+		}
+	} else {
+		for pos := 0; pos < len(rest) && strings.HasPrefix(strings.TrimLeft(rest[pos:], " \t"), "#"); {
+			next := strings.IndexByte(rest[pos:], '\n')
+			if next < 0 {
+				end = len(rest)
+				break
+			}
+			end = len(strings.TrimSuffix(rest[:pos+next], "\r"))
+			pos += next + 1
+		}
+	}
+	if end < 0 || !helpKeyword.MatchString(rest[:end]) || strings.TrimSpace(s.lineRest(start+end)) != "" {
+		return -1
+	}
+	return start + end
+}
+
+// indent returns the leading whitespace of the line holding offset
+func (s *sourceText) indent(offset int) string {
+	start := strings.LastIndexByte(s.text[:offset], '\n') + 1
+	line := s.text[start:]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// decodeSource decodes a file as PowerShell does: UTF-16 or UTF-8 by its byte order mark,
+// otherwise UTF-8. It fails for text in any other encoding, which --fix leaves alone.
+func decodeSource(data []byte) (string, string, error) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		data = data[len(bomUTF8):]
+		if utf8.Valid(data) {
+			return string(data), encodingUTF8BOM, nil
+		}
+	case bytes.HasPrefix(data, bomUTF16LE), bytes.HasPrefix(data, bomUTF16BE):
+		if len(data)%2 == 0 {
+			units := make([]uint16, 0, len(data)/2-1)
+			for i := 2; i < len(data); i += 2 {
+				if data[0] == bomUTF16LE[0] {
+					units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+				} else {
+					units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+				}
+			}
+			encoding := encodingUTF16LE
+			if data[0] == bomUTF16BE[0] {
+				encoding = encodingUTF16BE
+			}
+			return string(utf16.Decode(units)), encoding, nil
+		}
+	case utf8.Valid(data):
+		return string(data), encodingUTF8, nil
+	}
+	return "", "", errors.New(T("validate.fix_unknown_encoding"))
+}
+
+// encodeSource saves text in one of the encodings decodeSource reads
+func encodeSource(text, encoding string) []byte {
+	switch encoding {
+	case encodingUTF8BOM:
+		return append(append([]byte{}, bomUTF8...), text...)
+	case encodingUTF16LE, encodingUTF16BE:
+		units := utf16.Encode([]rune(text))
+		data := make([]byte, 0, 2+2*len(units))
+		if encoding == encodingUTF16LE {
+			data = append(data, bomUTF16LE...)
+			for _, unit := range units {
+				data = append(data, byte(unit), byte(unit>>8))
+			}
+		} else {
+			data = append(data, bomUTF16BE...)
+			for _, unit := range units {
+				data = append(data, byte(unit>>8), byte(unit))
+			}
+		}
+		return data
+	}
+	return []byte(text)
+}
+
+// needsBOM reports whether the encoding rule reports a file: PSScriptAnalyzer runs, the file
+// is UTF-8 without a byte order mark and has non-ASCII characters, and neither the
+// configuration nor a suppression turns the rule off
+func needsBOM(filename, text, encoding string, tree *SyntaxTree) bool {
+	if !analyzerEnabled || encoding != encodingUTF8 || utf8.RuneCountInString(text) == len(text) {
+		return false
+	}
+	findings := projectConfig.configure([]Finding{{RuleID: bomRule, Severity: severityWarning, File: filename, Source: sourceAnalyzer}})
+	if tree != nil {
+		findings = unsuppressed(findings, findSuppressions(tree))
+	}
+	return len(findings) > 0
+}
+
+// normalizeLineEndings makes every line end like the first one
+func normalizeLineEndings(text, newline string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if newline != "\n" {
+		text = strings.ReplaceAll(text, "\n", newline)
+	}
+	return text
+}
+
+// lineEndingName names a line ending for the fix description
+func lineEndingName(newline string) string {
+	if newline == "\r\n" {
+		return "CRLF"
+	}
+	return "LF"
+}
+
+// fixFile computes the fixes for a file: the rule fixes for the findings of tree, when the
+// file parsed, then consistent line endings. The file keeps its encoding, except for the
+// byte order mark the encoding rule asks for.
+func fixFile(filename string, tree *SyntaxTree) (*FileFix, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	text, encoding, err := decodeSource(data)
+	if err != nil {
+		return nil, err
+	}
+
+	fix := &FileFix{File: filename, Before: text}
+	source := newSourceText(text)
+	var edits []TextEdit
+	if tree != nil {
+		findings := append(runRules(tree, categoryCrossPlatform), runRules(tree, categoryBestPractices)...)
+		findings = unsuppressed(projectConfig.configure(findings), findSuppressions(tree))
+		sortFindings(findings)
+		for _, finding := range findings {
+			rule := builtinRule(finding.RuleID)
+			if rule.fix == nil {
+				continue
+			}
+			ruleEdits := rule.fix(source, tree, finding)
+			if len(ruleEdits) == 0 || overlapsEdits(edits, ruleEdits) {
+				continue
+			}
+			edits = append(edits, ruleEdits...)
+			fix.Fixes = append(fix.Fixes, fmt.Sprintf("%d:%d %s (%s)", finding.Line, finding.Column, finding.Message, finding.RuleID))
+		}
+	}
+
+	fixed := applyEdits(text, edits)
+	fix.After = normalizeLineEndings(fixed, source.newline)
+	if fix.After != fixed {
+		fix.Fixes = append(fix.Fixes, T("validate.fix_line_endings", lineEndingName(source.newline)))
+	}
+	newEncoding := encoding
+	if needsBOM(filename, fix.After, encoding, tree) {
+		newEncoding = encodingUTF8BOM
+		fix.Fixes = append(fix.Fixes, T("validate.fix_encoding", encoding, newEncoding))
+	}
+	fix.Data = encodeSource(fix.After, newEncoding)
+	return fix, nil
+}
+
+// unsuppressed returns the findings no suppression covers, so --fix never rewrites code the
+// author chose to keep
+func unsuppressed(findings []Finding, suppressions []*Suppression) []Finding {
+	kept := []Finding{}
+	for _, finding := range findings {
+		covered := false
+		for _, suppression := range suppressions {
+			if suppression.covers(finding) {
+				covered = true
+			}
+		}
+		if !covered {
+			kept = append(kept, finding)
+		}
+	}
+	return kept
+}
+
+// overlapsEdits reports whether any of next touches text that edits already change
+func overlapsEdits(edits, next []TextEdit) bool {
+	for _, a := range edits {
+		for _, b := range next {
+			if a.Start < b.End && b.Start < a.End || a.Start == b.Start {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyEdits applies non-overlapping edits to text
+func applyEdits(text string, edits []TextEdit) string {
+	sorted := append([]TextEdit{}, edits...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start > sorted[j].Start })
+	for _, edit := range sorted {
+		text = text[:edit.Start] + edit.Text + text[edit.End:]
+	}
+	return text
+}
+
+// functionAt returns the function a finding is located at
+func functionAt(tree *SyntaxTree, finding Finding) (FunctionNode, bool) {
+	for _, function := range tree.Functions {
+		if function.Line == finding.Line && function.Column == finding.Column {
+			return function, true
+		}
+	}
+	return FunctionNode{}, false
+}
+
+// fixCmdletBinding adds [CmdletBinding()] in front of a function's param() block. A function
+// reading $args is left alone, since an advanced function rejects undeclared arguments.
+func fixCmdletBinding(source *sourceText, tree *SyntaxTree, finding Finding) []TextEdit {
+	function, ok := functionAt(tree, finding)
+	if !ok || function.ParamBlock == (Extent{}) || function.UsesArgs {
+		return nil
+	}
+	start := source.offset(function.ParamBlock.Line, function.ParamBlock.Column)
+	if start < 0 {
+		return nil
+	}
+	indent := source.indent(start)
+	lineStart := strings.LastIndexByte(source.text[:start], '\n') + 1
+	if source.text[lineStart:start] == indent {
+		return []TextEdit{{Start: start, End: start, Text: "[CmdletBinding()]" + source.newline + indent}}
+	}
+	return []TextEdit{{Start: start, End: start, Text: "[CmdletBinding()] "}}
+}
+
+// fixParamBlock adds [CmdletBinding()] and an empty param() block at the top of a function
+// body that starts on its own line, after the comment-based help opening the body, which
+// Get-Help only finds there. Functions declaring parameters after their name, reading $args
+// and filters are left alone, as are bodies written on one line.
+func fixParamBlock(source *sourceText, tree *SyntaxTree, finding Finding) []TextEdit {
+	function, ok := functionAt(tree, finding)
+	if !ok || function.HasParameters || function.IsFilter || function.UsesArgs {
+		return nil
+	}
+	brace := source.offset(function.Body.Line, function.Body.Column)
+	if brace < 0 || source.text[brace] != '{' {
+		return nil
+	}
+	rest := source.lineRest(brace + 1)
+	if strings.TrimSpace(rest) != "" {
+		return nil
+	}
+
+	indent := source.indent(brace)
+	unit := "    "
+	if strings.Contains(indent, "\t") {
+		unit = "\t"
+	}
+	start, end := brace+1, brace+1+len(rest)
+	if help := source.helpEnd(end); help >= 0 {
+		start, end = help, help+len(source.lineRest(help))
+	}
+	text := source.newline + indent + unit + "[CmdletBinding()]" + source.newline + indent + unit + "param()"
+	return []TextEdit{{Start: start, End: end, Text: text}}
+}
+
+// fixAlias replaces a command alias with the command's full name
+func fixAlias(source *sourceText, tree *SyntaxTree, finding Finding) []TextEdit {
+	start := source.offset(finding.Line, finding.Column)
+	end := source.offset(finding.EndLine, finding.EndColumn)
+	if start < 0 || end < start {
+		return nil
+	}
+	name, ok := commandAliases[strings.ToLower(source.text[start:end])]
+	if !ok {
+		return nil
+	}
+	return []TextEdit{{Start: start, End: end, Text: name}}
+}
+
+// portablePathPatterns match Windows paths under a folder every platform has, with the
+// PowerShell expression for that folder. The first submatch is the rest of the path. Only
+// a user's own temporary folder is the one GetTempPath returns; C:\Temp and C:\Windows\Temp
+// are other folders.
+var portablePathPatterns = []struct {
+	pattern    *regexp.Regexp
+	expression string
+}{
+	{regexp.MustCompile(`(?i)^[a-z]:\\users\\[^\\]+\\appdata\\local\\temp(?:\\(.*))?$`), "([System.IO.Path]::GetTempPath())"},
+	{regexp.MustCompile(`(?i)^[a-z]:\\users\\(?:[^\\]+)(?:\\(.*))?$`), "$HOME"},
+}
+
+// sharedProfilePattern matches the Public and Default profiles, which are not a user's home
+var sharedProfilePattern = regexp.MustCompile(`(?i)^[a-z]:\\users\\(public|default)(\\|$)`)
+
+// portablePath returns a Join-Path expression for a Windows path under the user's home or
+// temporary folder, or false for any other path
+func portablePath(path string) (string, bool) {
+	if sharedProfilePattern.MatchString(path) {
+		return "", false
+	}
+	for _, portable := range portablePathPatterns {
+		match := portable.pattern.FindStringSubmatch(path)
+		if match == nil {
+			continue
+		}
+		rest := strings.Trim(strings.ReplaceAll(match[1], `\`, "/"), "/")
+		if rest == "" {
+			return portable.expression, true
+		}
+		return fmt.Sprintf("(Join-Path -Path %s -ChildPath '%s')", portable.expression, strings.ReplaceAll(rest, "'", "''")), true
+	}
+	return "", false
+}
+
+// fixWindowsPath replaces a hardcoded path under the user's home or temporary folder with a
+// Join-Path expression. Strings that expand variables or use escapes are left alone, and so
+// are data files and attribute arguments, which only take constants.
+func fixWindowsPath(source *sourceText, tree *SyntaxTree, finding Finding) []TextEdit {
+	if strings.EqualFold(filepath.Ext(tree.File), ".psd1") {
+		return nil
+	}
+	for _, attribute := range tree.Attributes {
+		if attribute.contains(finding.Line, finding.Column) {
+			return nil
+		}
+	}
+	for _, token := range tree.Tokens {
+		if token.Extent != (Extent{Line: finding.Line, Column: finding.Column, EndLine: finding.EndLine, EndColumn: finding.EndColumn}) {
+			continue
+		}
+		switch token.Kind {
+		case "StringLiteral", "StringExpandable", "Generic":
+		default:
+			return nil
+		}
+		if token.Kind != "StringLiteral" && strings.ContainsAny(token.Text, "$`") {
+			return nil
+		}
+		expression, ok := portablePath(token.Value)
+		start := source.offset(token.Line, token.Column)
+		end := source.offset(token.EndLine, token.EndColumn)
+		if !ok || start < 0 || end < start {
+			return nil
+		}
+		return []TextEdit{{Start: start, End: end, Text: expression}}
+	}
+	return nil
+}
+
+// fixFiles applies the safe fixes to files before they are validated, or with --dry-run
+// prints them as a unified diff without changing anything
+func fixFiles(out io.Writer, files []string, dryRun bool) {
+	var fixable []string
+	for _, file := range files {
+		if containsString(fixableExtensions, strings.ToLower(filepath.Ext(file))) {
+			fixable = append(fixable, file)
+		}
+	}
+	if len(fixable) == 0 {
+		return
+	}
+	trees, err := parsePowerShellFiles(fixable)
+	if err != nil {
+		fmt.Fprintln(out, T("validate.fix_failed", err))
+		return
+	}
+
+	fixedFiles, fixCount := 0, 0
+	for _, file := range fixable {
+		tree := trees[file]
+		if tree != nil && len(tree.Diagnostics) > 0 {
+			tree = nil
+		}
+		fix, err := fixFile(file, tree)
+		if err == nil && len(fix.Fixes) > 0 && !dryRun {
+			err = writeFileKeepingMode(file, fix.Data)
+		}
+		if err != nil {
+			fmt.Fprintln(out, T("validate.fix_skipped", file, err))
+			continue
+		}
+		if len(fix.Fixes) == 0 {
+			continue
+		}
+
+		fixedFiles++
+		fixCount += len(fix.Fixes)
+		if dryRun {
+			fmt.Fprintln(out, T("validate.fix_would_apply", len(fix.Fixes), file))
+		} else {
+			fmt.Fprintln(out, T("validate.fix_applied", len(fix.Fixes), file))
+		}
+		for _, description := range fix.Fixes {
+			fmt.Fprintf(out, "   • %s\n", description)
+		}
+		if dryRun {
+			fmt.Fprint(out, unifiedDiff(filepath.ToSlash(file), fix.Before, fix.After))
+		}
+	}
+
+	switch {
+	case fixCount == 0:
+		fmt.Fprintln(out, T("validate.fix_nothing"))
+	case dryRun:
+		fmt.Fprintln(out, T("validate.fix_dry_run_summary", fixCount, fixedFiles))
+	default:
+		fmt.Fprintln(out, T("validate.fix_summary", fixCount, fixedFiles))
+	}
+	fmt.Fprintln(out)
+}
+
+// writeFileKeepingMode replaces the content of an existing file without changing its
+// permissions
+func writeFileKeepingMode(filename string, data []byte) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, info.Mode().Perm())
+}
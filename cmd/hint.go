@@ -8,130 +8,178 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var hintNoAnalyze bool
+
 var hintCmd = &cobra.Command{
 Use:   "hint",
 Short: "Get contextual hints for the current step",
-Long:  `Provides helpful hints and guidance for your current PowerShell learning step`,
-Run: func(cmd *cobra.Command, args []string) {
-showHint()
+Long:  `Provides helpful hints and guidance for your current PowerShell learning step.
+
+Your .ps1 and .psm1 files are checked with validate's best practice rules so hints
+can address what you are likely stuck on, such as a function without a param()
+block, Write-Host or an empty catch block. Suppressed findings are left out.`,
+RunE: func(cmd *cobra.Command, args []string) error {
+return showHint()
 },
 }
 
 type Hint struct {
+ID          string
 Title       string
 Description string
 Example     string
 Reference   string
+Tags        []string
 }
 
+// hintCategories lists the hint categories in course order
+var hintCategories = []string{"fundamentals", "pipelines", "functions", "automation"}
+
 var powerShellHints = map[string][]Hint{
 "fundamentals": {
 {
+ID:          "fundamentals.variables",
 Title:       "Variables and Assignment",
 Description: "In PowerShell, variables start with $ and are dynamically typed",
 Example:     "$name = \"PowerShell\"; $number = 42",
 Reference:   "https://docs.microsoft.com/powershell/scripting/lang-spec/chapter-05",
+Tags:        []string{"variables", "assignment", "types", "basics"},
 },
 {
+ID:          "fundamentals.conditionals",
 Title:       "Conditional Logic",
 Description: "Use if/elseif/else for conditional execution",
 Example:     "if ($condition) { Write-Host \"True\" } else { Write-Host \"False\" }",
 Reference:   "https://docs.microsoft.com/powershell/scripting/lang-spec/chapter-08",
+Tags:        []string{"if", "else", "conditions", "comparison", "basics"},
 },
 },
 "pipelines": {
 {
+ID:          "pipelines.basics",
 Title:       "Pipeline Basics",
 Description: "PowerShell pipeline passes objects, not text. Use | to chain commands",
 Example:     "Get-Process | Where-Object { $_.CPU -gt 100 } | Select-Object Name, CPU",
 Reference:   "https://docs.microsoft.com/powershell/scripting/learn/understanding-the-powershell-pipeline",
+Tags:        []string{"pipeline", "objects", "select-object", "chaining"},
 },
 {
+ID:          "pipelines.filtering",
 Title:       "Filtering Objects",
 Description: "Where-Object filters objects based on conditions",
 Example:     "Get-Service | Where-Object Status -eq \"Running\"",
 Reference:   "https://docs.microsoft.com/powershell/module/microsoft.powershell.core/where-object",
+Tags:        []string{"pipeline", "filter", "where-object", "comparison"},
 },
 },
 "functions": {
 {
+ID:          "functions.definition",
 Title:       "Function Definition",
 Description: "Define reusable functions with param blocks and proper documentation",
 Example:     "function Get-SystemInfo { [CmdletBinding()] param() Get-ComputerInfo }",
 Reference:   "https://docs.microsoft.com/powershell/scripting/learn/ps101/09-functions",
+Tags:        []string{"function", "cmdletbinding", "param", "reuse"},
 },
 {
+ID:          "functions.parameter-validation",
 Title:       "Parameter Validation",
 Description: "Use parameter attributes for input validation",
 Example:     "[Parameter(Mandatory)] [ValidateNotNullOrEmpty()] [string]$Name",
 Reference:   "https://docs.microsoft.com/powershell/scripting/developer/cmdlet/validating-parameter-input",
+Tags:        []string{"parameters", "validation", "mandatory", "attributes"},
+},
+{
+ID:          "functions.output",
+Title:       "Writing Output",
+Description: "Write-Host only writes to the console; use Write-Output or simply emit values so results flow down the pipeline",
+Example:     "function Get-Greeting { param([string]$Name) Write-Output \"Hello, $Name\" }",
+Reference:   "https://docs.microsoft.com/powershell/module/microsoft.powershell.utility/write-output",
+Tags:        []string{"output", "write-output", "write-host", "pipeline", "return"},
 },
 },
 "automation": {
 {
+ID:          "automation.error-handling",
 Title:       "Error Handling",
 Description: "Use try/catch blocks for robust error handling",
 Example:     "try { Get-Item $path } catch { Write-Error \"File not found: $path\" }",
 Reference:   "https://docs.microsoft.com/powershell/scripting/learn/deep-dives/everything-about-exceptions",
+Tags:        []string{"errors", "exceptions", "try", "catch"},
 },
 {
+ID:          "automation.classes",
 Title:       "Classes and Objects",
 Description: "Define custom classes for complex automation scenarios",
 Example:     "class Server { [string]$Name [string]$Environment }",
 Reference:   "https://docs.microsoft.com/powershell/scripting/lang-spec/chapter-05#5.14-classes",
+Tags:        []string{"classes", "objects", "types", "properties"},
 },
 },
 }
 
-func showHint() {
-	fmt.Println("💡 PowerShell GitHub Skills - Contextual Hint")
+func showHint() error {
+	fmt.Println(T("hint.title"))
 	fmt.Println("=============================================")
 
 	// Detect current course context
 	courseType := detectCurrentCourse()
 	if courseType == "" {
-		fmt.Println("❌ Could not detect current course. Please run from a PowerShell Skills course directory.")
-		return
+		fmt.Println(T("common.no_current_course"))
+		return reportedError(exitNotInCourse, "no current course")
 	}
 
-	hints, exists := powerShellHints[courseType]
-	if !exists {
-		fmt.Printf("❌ No hints available for course type: %s\n", courseType)
-		return
+	hints := localizedHints(courseType)
+	if len(hints) == 0 {
+		fmt.Println(T("hint.no_hints", courseType))
+		return reportedError(exitFailure, "no hints for %s", courseType)
 	}
 
-	// Select a random hint from the appropriate course
-	// Use modern random number generation (Go 1.20+)
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	hint := hints[r.Intn(len(hints))]
-	
-	fmt.Printf("🎯 Topic: %s\n\n", hint.Title)
-	fmt.Printf("📝 Explanation:\n%s\n\n", hint.Description)
-	fmt.Printf("💻 Example:\n%s\n\n", hint.Example)
-	fmt.Printf("📚 Learn More: %s\n\n", hint.Reference)
+	// Prefer a hint addressing something found in the learner's own code,
+	// otherwise select a random hint from the appropriate course
+	var suggestion *HintSuggestion
+	if !hintNoAnalyze {
+		suggestion = suggestHintFromCode(courseType)
+	}
+
+	var hint Hint
+	if suggestion != nil {
+		hint = suggestion.Hint
+		printCodeTriggers(suggestion)
+	} else {
+		// Use modern random number generation (Go 1.20+)
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		hint = hints[r.Intn(len(hints))]
+	}
+
+	fmt.Printf("%s\n\n", T("hint.topic", hint.Title))
+	fmt.Printf("%s\n%s\n\n", T("hint.explanation"), hint.Description)
+	fmt.Printf("%s\n%s\n\n", T("hint.example"), hint.Example)
+	fmt.Printf("%s\n\n", T("hint.learn_more", hint.Reference))
 
 	// Additional context-aware tips
-	fmt.Println("🔧 Pro Tips:")
+	fmt.Println(T("hint.pro_tips"))
 	switch courseType {
 	case "fundamentals":
-		fmt.Println("• Use Get-Help <command> to learn about any PowerShell command")
-		fmt.Println("• PowerShell is case-insensitive for commands and variables")
-		fmt.Println("• Use tab completion to discover available commands and parameters")
+		fmt.Println("• " + T("hint.tip.fundamentals.1"))
+		fmt.Println("• " + T("hint.tip.fundamentals.2"))
+		fmt.Println("• " + T("hint.tip.fundamentals.3"))
 	case "pipelines":
-		fmt.Println("• Remember: PowerShell passes objects, not text through the pipeline")
-		fmt.Println("• Use Get-Member to explore object properties and methods")
-		fmt.Println("• ForEach-Object processes each pipeline object individually")
+		fmt.Println("• " + T("hint.tip.pipelines.1"))
+		fmt.Println("• " + T("hint.tip.pipelines.2"))
+		fmt.Println("• " + T("hint.tip.pipelines.3"))
 	case "functions":
-		fmt.Println("• Always include [CmdletBinding()] for advanced function features")
-		fmt.Println("• Use Write-Verbose for debugging instead of Write-Host")
-		fmt.Println("• Return objects, not formatted text from functions")
+		fmt.Println("• " + T("hint.tip.functions.1"))
+		fmt.Println("• " + T("hint.tip.functions.2"))
+		fmt.Println("• " + T("hint.tip.functions.3"))
 	case "automation":
-		fmt.Println("• Use PowerShell classes for complex data structures")
-		fmt.Println("• Implement proper error handling with try/catch/finally")
-		fmt.Println("• Consider security implications when automating sensitive operations")
+		fmt.Println("• " + T("hint.tip.automation.1"))
+		fmt.Println("• " + T("hint.tip.automation.2"))
+		fmt.Println("• " + T("hint.tip.automation.3"))
 	}
 
-	fmt.Println("\n🚀 Ready to continue? Use 'gh pwsh-skills validate' to test your solution!")
+	fmt.Println("\n" + T("hint.ready"))
+	return nil
 }
 
 func detectCurrentCourse() string {
@@ -141,21 +189,14 @@ func detectCurrentCourse() string {
 		return ""
 	}
 	
-	// Map course names to hint categories
-	switch currentCourse.Index {
-	case 0:
-		return "fundamentals"
-	case 1:
-		return "pipelines"
-	case 2:
-		return "functions"
-	case 3:
-		return "automation"
-	default:
+	// Map course index to hint category
+	if currentCourse.Index < 0 || currentCourse.Index >= len(hintCategories) {
 		return ""
 	}
+	return hintCategories[currentCourse.Index]
 }
 
 func init() {
+	hintCmd.Flags().BoolVar(&hintNoAnalyze, "no-analyze", false, "Don't analyse your .ps1 files; show a random hint for the course")
 	rootCmd.AddCommand(hintCmd)
 }
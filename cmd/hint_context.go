@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CodeFinding is something in the learner's code that a hint can address
+type CodeFinding struct {
+	Kind   string `json:"kind"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Name   string `json:"name"`
+}
+
+// HintSuggestion is a hint chosen because of findings in the learner's code
+type HintSuggestion struct {
+	Hint     Hint
+	Category string
+	Triggers []CodeFinding
+	Others   []CodeFinding
+}
+
+// Finding kinds a hint can address
+const (
+	findingMissingParamBlock    = "missing-param-block"
+	findingMissingCmdletBinding = "missing-cmdletbinding"
+	findingWriteHost            = "write-host"
+	findingEmptyCatch           = "empty-catch"
+)
+
+// ruleFindingKinds maps the built-in rules whose findings a hint can address to the kind
+// of finding they report
+var ruleFindingKinds = map[string]string{
+	"BP003": findingMissingParamBlock,
+	"BP001": findingMissingCmdletBinding,
+	"BP002": findingWriteHost,
+}
+
+// findingHints maps each finding kind to the hint that addresses it
+var findingHints = map[string]string{
+	findingMissingParamBlock:    "functions.definition",
+	findingMissingCmdletBinding: "functions.definition",
+	findingWriteHost:            "functions.output",
+	findingEmptyCatch:           "automation.error-handling",
+}
+
+// suggestHintFromCode analyses the learner's scripts and script modules and returns a hint
+// addressing what they are most likely stuck on, or nil when nothing was found or PowerShell
+// is unavailable
+func suggestHintFromCode(courseType string) *HintSuggestion {
+	// The configuration only tunes the rules here, so one that is not valid leaves the defaults
+	loadProjectConfig()
+
+	var files []string
+	for _, file := range findPowerShellFiles() {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".ps1", ".psm1":
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	trees, err := parsePowerShellFiles(files)
+	if err != nil {
+		return nil
+	}
+	var findings []CodeFinding
+	for _, file := range files {
+		if tree := trees[file]; tree != nil {
+			findings = append(findings, codeFindings(tree)...)
+		}
+	}
+	return selectHintForFindings(findings, courseType)
+}
+
+// codeFindings returns what a hint can address in a parsed file: the best practice findings
+// validate reports for it, leaving out suppressed ones and rules turned off, and its empty
+// catch blocks
+func codeFindings(tree *SyntaxTree) []CodeFinding {
+	var findings []CodeFinding
+	ruleFindings := unsuppressed(projectConfig.configure(runRules(tree, categoryBestPractices)), findSuppressions(tree))
+	for _, finding := range ruleFindings {
+		kind, ok := ruleFindingKinds[finding.RuleID]
+		if !ok {
+			continue
+		}
+		codeFinding := CodeFinding{Kind: kind, File: tree.File, Line: finding.Line, Column: finding.Column}
+		for _, function := range tree.Functions {
+			if function.Line == finding.Line && function.Column == finding.Column {
+				codeFinding.Name = function.Name
+			}
+		}
+		findings = append(findings, codeFinding)
+	}
+	for _, extent := range emptyCatches(tree) {
+		findings = append(findings, CodeFinding{Kind: findingEmptyCatch, File: tree.File, Line: extent.Line, Column: extent.Column})
+	}
+	return findings
+}
+
+// emptyCatches returns the catch keywords of catch blocks holding nothing but comments
+func emptyCatches(tree *SyntaxTree) []Extent {
+	var catches []Extent
+	for i, token := range tree.Tokens {
+		if token.Kind != "Catch" {
+			continue
+		}
+		// Skip the exception types up to the block's opening brace, then its comments
+		next := i + 1
+		for next < len(tree.Tokens) && tree.Tokens[next].Kind != "LCurly" {
+			next++
+		}
+		next++
+		for next < len(tree.Tokens) && tree.Tokens[next].Kind == "Comment" {
+			next++
+		}
+		if next < len(tree.Tokens) && tree.Tokens[next].Kind == "RCurly" {
+			catches = append(catches, token.Extent)
+		}
+	}
+	return catches
+}
+
+// selectHintForFindings picks the hint addressing the most findings, preferring hints
+// from the current course and breaking ties by the earliest finding
+func selectHintForFindings(findings []CodeFinding, courseType string) *HintSuggestion {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+
+	type candidate struct {
+		hintID   string
+		category string
+		triggers []CodeFinding
+		first    int
+	}
+	candidates := map[string]*candidate{}
+	var order []string
+	for i, finding := range findings {
+		hintID, ok := findingHints[finding.Kind]
+		if !ok {
+			continue
+		}
+		c, ok := candidates[hintID]
+		if !ok {
+			_, category, _ := findHint(hintID)
+			c = &candidate{hintID: hintID, category: category, first: i}
+			candidates[hintID] = c
+			order = append(order, hintID)
+		}
+		c.triggers = append(c.triggers, finding)
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := candidates[order[i]], candidates[order[j]]
+		if (a.category == courseType) != (b.category == courseType) {
+			return a.category == courseType
+		}
+		if len(a.triggers) != len(b.triggers) {
+			return len(a.triggers) > len(b.triggers)
+		}
+		return a.first < b.first
+	})
+
+	best := candidates[order[0]]
+	hint, _, ok := findHint(best.hintID)
+	if !ok {
+		return nil
+	}
+
+	suggestion := &HintSuggestion{Hint: localizeHint(hint), Category: best.category, Triggers: best.triggers}
+	for _, hintID := range order[1:] {
+		suggestion.Others = append(suggestion.Others, candidates[hintID].triggers...)
+	}
+	return suggestion
+}
+
+// findHint returns the hint with the given ID and the category it belongs to
+func findHint(id string) (Hint, string, bool) {
+	for _, category := range hintCategories {
+		for _, hint := range powerShellHints[category] {
+			if hint.ID == id {
+				return hint, category, true
+			}
+		}
+	}
+	return Hint{}, "", false
+}
+
+// describeFinding explains a finding in the current locale
+func describeFinding(finding CodeFinding) string {
+	switch finding.Kind {
+	case findingMissingParamBlock, findingMissingCmdletBinding:
+		return T("hint.finding."+finding.Kind, finding.Name)
+	default:
+		return T("hint.finding." + finding.Kind)
+	}
+}
+
+// printCodeTriggers explains which files and lines led to the suggested hint
+func printCodeTriggers(suggestion *HintSuggestion) {
+	fmt.Println(T("hint.based_on_code"))
+	for _, finding := range suggestion.Triggers {
+		fmt.Printf("   • %s:%d - %s\n", finding.File, finding.Line, describeFinding(finding))
+	}
+	if len(suggestion.Others) > 0 {
+		fmt.Println(T("hint.also_noticed"))
+		for _, finding := range suggestion.Others {
+			fmt.Printf("   • %s:%d - %s\n", finding.File, finding.Line, describeFinding(finding))
+		}
+	}
+	fmt.Println()
+}
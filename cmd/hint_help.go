@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var helpRefresh bool
+
+var hintCmdletCmd = &cobra.Command{
+	Use:   "cmdlet <Name>",
+	Short: "Show offline help for a cmdlet from your local PowerShell",
+	Long: `Look up a cmdlet, function or alias with Get-Command and Get-Help in your local
+PowerShell and show its synopsis, syntax, parameters and examples.
+
+Results are cached per PowerShell version, so repeated lookups are instant.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showCmdletHelp(args[0])
+	},
+}
+
+var hintAboutCmd = &cobra.Command{
+	Use:   "about <topic>",
+	Short: "Show an about_ help topic from your local PowerShell",
+	Long: `Show a conceptual about_ help topic, such as about_Pipelines or about_Functions,
+from your local PowerShell help. The about_ prefix is optional.
+
+Run Update-Help in PowerShell once to install the help content.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showAboutTopic(args[0])
+	},
+}
+
+// CmdletHelp is the structured help for a command
+type CmdletHelp struct {
+	Found       bool              `json:"found"`
+	Name        string            `json:"name"`
+	CommandType string            `json:"commandType"`
+	Module      string            `json:"module"`
+	Synopsis    string            `json:"synopsis"`
+	Description string            `json:"description"`
+	Syntax      []string          `json:"syntax"`
+	Parameters  []CmdletParameter `json:"parameters"`
+	Examples    []CmdletExample   `json:"examples"`
+	HelpURI     string            `json:"helpUri"`
+}
+
+// CmdletParameter describes one parameter of a command
+type CmdletParameter struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Required      bool   `json:"required"`
+	Position      string `json:"position"`
+	PipelineInput string `json:"pipelineInput"`
+	Description   string `json:"description"`
+}
+
+// CmdletExample is one example from a command's help
+type CmdletExample struct {
+	Title   string `json:"title"`
+	Code    string `json:"code"`
+	Remarks string `json:"remarks"`
+}
+
+// AboutTopic is a conceptual help topic split into its sections
+type AboutTopic struct {
+	Found    bool           `json:"found"`
+	Name     string         `json:"name"`
+	Synopsis string         `json:"synopsis"`
+	Sections []AboutSection `json:"sections"`
+}
+
+// AboutSection is a titled section of an about_ topic
+type AboutSection struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+var (
+	commandNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+	aboutHeadingLine   = regexp.MustCompile(`^(#{1,3}\s+.+|[A-Z][A-Z0-9 ,\-]+)$`)
+)
+
+func showCmdletHelp(name string) error {
+	fmt.Println(T("help.cmdlet_title"))
+	fmt.Println("=============================================")
+
+	if !commandNamePattern.MatchString(name) {
+		fmt.Println(T("help.invalid_name", name))
+		return reportedError(exitUsage, "invalid command name %q", name)
+	}
+
+	help, err := lookupCmdletHelp(name, helpRefresh)
+	if err != nil {
+		return printHelpLookupError(err)
+	}
+	if !help.Found {
+		fmt.Println(T("help.cmdlet_not_found", name))
+		fmt.Println(T("help.try_search", name))
+		return reportedError(exitFailure, "command %s not found", name)
+	}
+
+	renderCmdletHelp(help)
+	return nil
+}
+
+func showAboutTopic(topic string) error {
+	fmt.Println(T("help.about_title"))
+	fmt.Println("=============================================")
+
+	if !commandNamePattern.MatchString(topic) {
+		fmt.Println(T("help.invalid_name", topic))
+		return reportedError(exitUsage, "invalid topic name %q", topic)
+	}
+	if !strings.HasPrefix(strings.ToLower(topic), "about_") {
+		topic = "about_" + topic
+	}
+
+	about, err := lookupAboutTopic(topic, helpRefresh)
+	if err != nil {
+		return printHelpLookupError(err)
+	}
+	if !about.Found {
+		fmt.Println(T("help.about_not_found", topic))
+		fmt.Println(T("help.update_help"))
+		return reportedError(exitFailure, "help topic %s not found", topic)
+	}
+
+	renderAboutTopic(about)
+	return nil
+}
+
+// printHelpLookupError explains why a help lookup failed and returns err as a reported
+// environment problem, since the lookup only fails when PowerShell cannot be used
+func printHelpLookupError(err error) error {
+	if errors.Is(err, errPowerShellNotFound) {
+		fmt.Println(T("validate.pwsh_missing"))
+		fmt.Println("   " + T("validate.pwsh_install"))
+	} else {
+		fmt.Println(T("help.lookup_error", err))
+	}
+	return reported(exitError(exitEnvironment, err))
+}
+
+func renderCmdletHelp(help *CmdletHelp) {
+	heading := help.Name
+	if help.Module != "" {
+		heading = fmt.Sprintf("%s (%s)", help.Name, help.Module)
+	}
+	fmt.Printf("%s\n\n", T("help.command", heading))
+
+	if help.Synopsis != "" {
+		fmt.Printf("%s\n%s\n\n", T("help.synopsis"), help.Synopsis)
+	}
+
+	if len(help.Syntax) > 0 {
+		fmt.Println(T("help.syntax"))
+		for _, syntax := range help.Syntax {
+			fmt.Printf("%s\n", syntax)
+		}
+		fmt.Println()
+	}
+
+	if len(help.Parameters) > 0 {
+		fmt.Println(T("help.parameters"))
+		for _, parameter := range help.Parameters {
+			details := []string{}
+			if parameter.Required {
+				details = append(details, T("help.parameter_required"))
+			}
+			if parameter.Position != "" && !strings.EqualFold(parameter.Position, "named") {
+				details = append(details, T("help.parameter_position", parameter.Position))
+			}
+			if strings.HasPrefix(strings.ToLower(parameter.PipelineInput), "true") {
+				details = append(details, T("help.parameter_pipeline"))
+			}
+
+			line := fmt.Sprintf("  -%s <%s>", parameter.Name, parameter.Type)
+			if len(details) > 0 {
+				line += " (" + strings.Join(details, ", ") + ")"
+			}
+			fmt.Println(line)
+			if parameter.Description != "" {
+				fmt.Printf("     %s\n", firstParagraph(parameter.Description))
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(help.Examples) > 0 {
+		fmt.Println(T("help.examples"))
+		for _, example := range help.Examples {
+			if example.Title != "" {
+				fmt.Printf("  %s\n", example.Title)
+			}
+			for _, line := range strings.Split(example.Code, "\n") {
+				fmt.Printf("  %s\n", strings.TrimRight(line, "\r"))
+			}
+			if example.Remarks != "" {
+				fmt.Printf("     %s\n", firstParagraph(example.Remarks))
+			}
+			fmt.Println()
+		}
+	}
+
+	if help.HelpURI != "" {
+		fmt.Printf("%s\n\n", T("hint.learn_more", help.HelpURI))
+	}
+	if len(help.Examples) == 0 && help.Synopsis == "" {
+		fmt.Println(T("help.update_help"))
+	}
+}
+
+func renderAboutTopic(about *AboutTopic) {
+	fmt.Printf("%s\n\n", T("help.topic", about.Name))
+	if about.Synopsis != "" {
+		fmt.Printf("%s\n%s\n\n", T("help.synopsis"), about.Synopsis)
+	}
+	for _, section := range about.Sections {
+		fmt.Printf("📖 %s\n%s\n\n", section.Title, section.Text)
+	}
+}
+
+// firstParagraph returns the text up to the first blank line
+func firstParagraph(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if i := strings.Index(text, "\n\n"); i >= 0 {
+		text = text[:i]
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// lookupCmdletHelp returns help for a command, using the per-version cache unless refresh is set
+func lookupCmdletHelp(name string, refresh bool) (*CmdletHelp, error) {
+	var help CmdletHelp
+	err := cachedHelpLookup("cmdlet", name, refresh, &help, func() ([]byte, error) {
+		return runPowerShellScript("cmdlet-help.ps1", scriptParams{"Name": name})
+	}, func(output []byte) (bool, error) {
+		err := json.Unmarshal(output, &help)
+		return help.Found, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &help, nil
+}
+
+// lookupAboutTopic returns a parsed about_ topic, using the per-version cache unless refresh is set
+func lookupAboutTopic(topic string, refresh bool) (*AboutTopic, error) {
+	var about AboutTopic
+	err := cachedHelpLookup("about", topic, refresh, &about, func() ([]byte, error) {
+		return runPowerShellScript("about-help.ps1", scriptParams{"Name": topic})
+	}, func(output []byte) (bool, error) {
+		var raw struct {
+			Found bool   `json:"found"`
+			Name  string `json:"name"`
+			Text  string `json:"text"`
+		}
+		if err := json.Unmarshal(output, &raw); err != nil {
+			return false, err
+		}
+		if raw.Found {
+			about = parseAboutTopic(raw.Name, raw.Text)
+		}
+		return raw.Found, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &about, nil
+}
+
+// cachedHelpLookup loads a help entry from the cache or runs fetch, parses the output and
+// stores the parsed result when parse reports it was found. Entries are keyed by PowerShell
+// version so upgrades refresh them, and misses are not cached so Update-Help takes effect.
+func cachedHelpLookup(kind, name string, refresh bool, result interface{}, fetch func() ([]byte, error), parse func([]byte) (bool, error)) error {
+	version, err := powerShellVersion()
+	if err != nil {
+		return err
+	}
+
+	cacheFile := ""
+	if dir, err := cacheDir(); err == nil {
+		cacheFile = helpCachePath(dir, version, kind, name)
+		if !refresh {
+			if data, err := os.ReadFile(cacheFile); err == nil && json.Unmarshal(data, result) == nil {
+				return nil
+			}
+		}
+	}
+
+	output, err := fetch()
+	if err != nil {
+		return err
+	}
+	found, err := parse(output)
+	if err != nil {
+		return fmt.Errorf("could not parse PowerShell help output: %w", err)
+	}
+
+	if found && cacheFile != "" {
+		if data, err := json.Marshal(result); err == nil {
+			_ = writeFileWithDirs(cacheFile, data)
+		}
+	}
+	return nil
+}
+
+// helpCachePath returns the cache file for a help entry of the given PowerShell version
+func helpCachePath(dir, version, kind, name string) string {
+	return filepath.Join(dir, "help", version, kind+"-"+strings.ToLower(name)+".json")
+}
+
+// parseAboutTopic splits an about_ topic into its synopsis and titled sections.
+// Both the classic upper-case headings and the newer Markdown headings are recognised.
+func parseAboutTopic(name, text string) AboutTopic {
+	about := AboutTopic{Found: true, Name: name}
+
+	var current *AboutSection
+	var body []string
+	flush := func() {
+		if current != nil {
+			current.Text = strings.TrimSpace(strings.Join(body, "\n"))
+			about.Sections = append(about.Sections, *current)
+		}
+		body = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && line == strings.TrimLeft(line, " \t") && aboutHeadingLine.MatchString(trimmed) {
+			title := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			if strings.EqualFold(title, name) || strings.EqualFold(title, "TOPIC") {
+				flush()
+				current = nil
+				continue
+			}
+			flush()
+			current = &AboutSection{Title: title}
+			continue
+		}
+		if current != nil {
+			body = append(body, strings.TrimRight(line, " \t"))
+		}
+	}
+	flush()
+
+	sections := about.Sections[:0]
+	for _, section := range about.Sections {
+		if strings.EqualFold(section.Title, "SHORT DESCRIPTION") && about.Synopsis == "" {
+			about.Synopsis = firstParagraph(section.Text)
+			continue
+		}
+		if section.Text != "" {
+			sections = append(sections, section)
+		}
+	}
+	about.Sections = sections
+	return about
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{hintCmdletCmd, hintAboutCmd} {
+		cmd.Flags().BoolVar(&helpRefresh, "refresh", false, "Ignore cached help and query PowerShell again")
+		hintCmd.AddCommand(cmd)
+	}
+}
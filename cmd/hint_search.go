@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var hintSearchLimit int
+
+var hintSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search hints across all PowerShell courses",
+	Long: `Search hint titles, descriptions, examples and tags across every PowerShell course.
+
+Works from any directory - no course repository is required.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showHintSearch(strings.Join(args, " "), hintSearchLimit)
+	},
+}
+
+// HintMatch is a hint found by a search together with its relevance score
+type HintMatch struct {
+	Category string
+	Hint     Hint
+	Score    int
+}
+
+// Field weights used to rank search results
+const (
+	titleWeight       = 5
+	tagWeight         = 4
+	descriptionWeight = 2
+	exampleWeight     = 1
+)
+
+// searchStopWords are ignored in queries so "that pipeline thing" searches for "pipeline"
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "that": true, "this": true,
+	"thing": true, "stuff": true, "how": true, "to": true, "do": true, "i": true,
+	"in": true, "of": true, "on": true, "with": true, "what": true, "is": true,
+	"for": true, "use": true,
+}
+
+// showHintSearch prints the hints matching query, best first, and fails when none match
+func showHintSearch(query string, limit int) error {
+	fmt.Println(T("search.title"))
+	fmt.Println("=============================================")
+
+	matches := SearchHints(query)
+	if len(matches) == 0 {
+		fmt.Println(T("search.no_results", query))
+		fmt.Println(T("search.try_again"))
+		return reportedError(exitFailure, "no hints match %q", query)
+	}
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	fmt.Printf("%s\n\n", T("search.found", len(matches), query))
+	for i, match := range matches {
+		fmt.Printf("%d. 🎯 %s (%s)\n", i+1, match.Hint.Title, hintCategoryCourseName(match.Category))
+		fmt.Printf("   📝 %s\n", match.Hint.Description)
+		fmt.Printf("   💻 %s\n", match.Hint.Example)
+		fmt.Printf("   📚 %s\n\n", match.Hint.Reference)
+	}
+	return nil
+}
+
+// SearchHints searches every course's hints and returns matches ranked by relevance
+func SearchHints(query string) []HintMatch {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matches []HintMatch
+	for _, category := range hintCategories {
+		for _, hint := range localizedHints(category) {
+			score, matched := scoreHint(hint, terms, query)
+			if matched == 0 {
+				continue
+			}
+			// Hints matching every term rank above hints matching only some
+			score = score * matched / len(terms)
+			if matched == len(terms) {
+				score += 10
+			}
+			matches = append(matches, HintMatch{Category: category, Hint: hint, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches
+}
+
+// scoreHint returns the weighted score of a hint and how many terms it matched
+func scoreHint(hint Hint, terms []string, query string) (score int, matched int) {
+	title := strings.ToLower(hint.Title)
+	description := strings.ToLower(hint.Description)
+	example := strings.ToLower(hint.Example)
+
+	for _, term := range terms {
+		termScore := 0
+		if strings.Contains(title, term) {
+			termScore += titleWeight
+		}
+		for _, tag := range hint.Tags {
+			if strings.HasPrefix(strings.ToLower(tag), term) {
+				termScore += tagWeight
+				break
+			}
+		}
+		if strings.Contains(description, term) {
+			termScore += descriptionWeight
+		}
+		if strings.Contains(example, term) {
+			termScore += exampleWeight
+		}
+		if termScore > 0 {
+			matched++
+			score += termScore
+		}
+	}
+
+	// Reward an exact phrase match in the title
+	if phrase := strings.ToLower(strings.TrimSpace(query)); len(terms) > 1 && strings.Contains(title, phrase) {
+		score += titleWeight * 2
+	}
+
+	return score, matched
+}
+
+// searchTerms splits a query into lowercase terms, keeping cmdlet names like Where-Object intact
+func searchTerms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+
+	var terms []string
+	seen := map[string]bool{}
+	for _, field := range fields {
+		field = strings.Trim(field, "-")
+		if field == "" || searchStopWords[field] || seen[field] {
+			continue
+		}
+		seen[field] = true
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+// hintCategoryCourseName returns the course name a hint category belongs to
+func hintCategoryCourseName(category string) string {
+	courses := GetAllCoursesInfo()
+	for i, c := range hintCategories {
+		if c == category && i < len(courses) {
+			return courses[i].Name
+		}
+	}
+	return category
+}
+
+func init() {
+	hintSearchCmd.Flags().IntVarP(&hintSearchLimit, "limit", "n", 5, "Maximum number of results to show (0 for all)")
+	hintCmd.AddCommand(hintSearchCmd)
+}
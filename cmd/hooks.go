@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies the hooks installed by this command
+const hookMarker = "# Installed by gh pwsh-skills hooks install"
+
+// chainedHookSuffix is appended to the name of a hook that was installed before ours; our
+// hook runs it first and uninstall puts it back
+const chainedHookSuffix = ".pwsh-skills-chained"
+
+// managedHooks are the hooks installed, with the validation each runs. pre-commit checks what
+// is about to be committed. pre-push reads the refs being pushed from stdin and checks the
+// committed content of what each one sends: the commits the remote does not have yet, or
+// every file of a branch the remote does not know. Deleted branches are skipped.
+var managedHooks = []struct {
+	name     string
+	stdin    bool
+	validate string
+}{
+	{"pre-commit", false, "gh pwsh-skills validate --staged"},
+	{"pre-push", true, `printf '%s\n' "$input" | while read -r local_ref local_sha remote_ref remote_sha; do
+    case "$local_sha" in *[!0]*) ;; *) continue ;; esac
+    commits="$local_sha"
+    if git cat-file -e "$remote_sha^{commit}" 2>/dev/null; then
+        commits="$remote_sha..$local_sha"
+    fi
+    gh pwsh-skills validate --commits="$commits" </dev/null || exit $?
+done`},
+}
+
+// hookScript is the shell script of a hook; git runs hooks with sh on every platform.
+// Validation that cannot run because PowerShell is missing (exit code 3) does not block.
+// A hook reading stdin keeps it in $input, so the chained hook and validation both see it.
+const hookScript = `#!/bin/sh
+%[1]s; remove with gh pwsh-skills hooks uninstall.
+# Runs the %[2]s hook that was here before, then validates the PowerShell files.
+%[5]schained="$(dirname "$0")/%[2]s%[3]s"
+if [ -x "$chained" ]; then
+    %[6]s"$chained" "$@" || exit $?
+fi
+if ! command -v gh >/dev/null 2>&1; then
+    echo "pwsh-skills: gh not found, skipping PowerShell validation" >&2
+    exit 0
+fi
+%[4]s
+status=$?
+if [ "$status" -eq 3 ]; then
+    echo "pwsh-skills: PowerShell is not available, skipping validation" >&2
+    exit 0
+fi
+if [ "$status" -ne 0 ]; then
+    echo "pwsh-skills: fix the problems above, or skip the check once with --no-verify" >&2
+fi
+exit "$status"
+`
+
+// Hook states reported by hooks status
+const (
+	hookInstalled = "installed"
+	hookChained   = "chained"
+	hookForeign   = "foreign"
+	hookMissing   = "missing"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Validate before every commit and push with git hooks",
+	Long: `Install git hooks that validate your PowerShell files before they leave your machine,
+so broken scripts are caught before the Skills workflow fails on them.
+
+The pre-commit hook runs "validate --staged" on what is about to be committed; the
+pre-push hook runs "validate --commits" on the committed content of the commits being
+pushed, or of every file for a branch new to the remote. Hooks already
+in place are kept and run first. The hooks directory is taken from git, so
+core.hooksPath and worktrees are respected.`,
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the pre-commit and pre-push hooks",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return installHooks(os.Stdout)
+	},
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the hooks and restore the ones they replaced",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return uninstallHooks(os.Stdout)
+	},
+}
+
+var hooksStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the hooks are installed",
+	Long: `Show whether the pre-commit and pre-push hooks are installed. Exits with 1 when one
+of them is not.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printHookStatus(os.Stdout)
+	},
+}
+
+// hooksDir returns the directory git runs hooks from, honouring core.hooksPath and the
+// hooks shared by every worktree of a repository
+func hooksDir() (string, error) {
+	output, err := runGit("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		if ExitCode(err) == exitEnvironment {
+			return "", err
+		}
+		return "", exitError(exitNotInCourse, errors.New("not in a git repository"))
+	}
+	return filepath.Clean(strings.TrimSpace(string(output))), nil
+}
+
+// isManagedHook reports whether the hook at path was installed by this command
+func isManagedHook(path string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(content), hookMarker)
+}
+
+// hookState returns the state of a hook in dir
+func hookState(dir, name string) string {
+	path := filepath.Join(dir, name)
+	if isManagedHook(path) {
+		if _, err := os.Stat(path + chainedHookSuffix); err == nil {
+			return hookChained
+		}
+		return hookInstalled
+	}
+	if _, err := os.Stat(path); err == nil {
+		return hookForeign
+	}
+	return hookMissing
+}
+
+// installHooks writes the hooks, moving a hook that is not ours aside to run it first.
+// Installing again updates our hooks and keeps the chained ones.
+func installHooks(out io.Writer) error {
+	dir, err := hooksDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, T("hooks.title"))
+	fmt.Fprintln(out, T("hooks.directory", dir))
+	for _, hook := range managedHooks {
+		path := filepath.Join(dir, hook.name)
+		if hookState(dir, hook.name) == hookForeign {
+			if _, err := os.Stat(path + chainedHookSuffix); err == nil {
+				return fmt.Errorf("cannot keep the existing %s hook: %s already exists", hook.name, path+chainedHookSuffix)
+			}
+			if err := os.Rename(path, path+chainedHookSuffix); err != nil {
+				return err
+			}
+			fmt.Fprintln(out, T("hooks.installed", hook.name))
+			fmt.Fprintln(out, "   "+T("hooks.chained", hook.name, path+chainedHookSuffix))
+		} else {
+			fmt.Fprintln(out, T("hooks.installed", hook.name))
+		}
+
+		read, feed := "", ""
+		if hook.stdin {
+			read, feed = "input=$(cat)\n", `printf '%s\n' "$input" | `
+		}
+		script := fmt.Sprintf(hookScript, hookMarker, hook.name, chainedHookSuffix, hook.validate, read, feed)
+		if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+			return err
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(path, 0o755); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, T("hooks.bypass_tip"))
+	return nil
+}
+
+// uninstallHooks removes our hooks and puts back the hooks they chained. Hooks installed
+// by something else are left alone.
+func uninstallHooks(out io.Writer) error {
+	dir, err := hooksDir()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, T("hooks.title"))
+	for _, hook := range managedHooks {
+		path := filepath.Join(dir, hook.name)
+		switch hookState(dir, hook.name) {
+		case hookMissing:
+			fmt.Fprintln(out, T("hooks.not_installed", hook.name))
+		case hookForeign:
+			fmt.Fprintln(out, T("hooks.foreign", hook.name))
+		case hookInstalled:
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			fmt.Fprintln(out, T("hooks.uninstalled", hook.name))
+		case hookChained:
+			if err := os.Rename(path+chainedHookSuffix, path); err != nil {
+				return err
+			}
+			fmt.Fprintln(out, T("hooks.uninstalled", hook.name))
+			fmt.Fprintln(out, "   "+T("hooks.restored", hook.name))
+		}
+	}
+	return nil
+}
+
+// printHookStatus shows the state of each hook and fails when one is not installed
+func printHookStatus(out io.Writer) error {
+	dir, err := hooksDir()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, T("hooks.title"))
+	fmt.Fprintln(out, T("hooks.directory", dir))
+	missing := 0
+	for _, hook := range managedHooks {
+		switch hookState(dir, hook.name) {
+		case hookInstalled:
+			fmt.Fprintln(out, T("hooks.status_installed", hook.name))
+		case hookChained:
+			fmt.Fprintln(out, T("hooks.status_chained", hook.name))
+		case hookForeign:
+			fmt.Fprintln(out, T("hooks.status_foreign", hook.name))
+			missing++
+		case hookMissing:
+			fmt.Fprintln(out, T("hooks.status_missing", hook.name))
+			missing++
+		}
+	}
+	if missing > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, T("hooks.install_tip"))
+		return reportedError(exitFailure, "%d of %d hooks not installed", missing, len(managedHooks))
+	}
+	return nil
+}
+
+func init() {
+	hooksCmd.AddCommand(hooksInstallCmd, hooksUninstallCmd, hooksStatusCmd)
+	rootCmd.AddCommand(hooksCmd)
+}
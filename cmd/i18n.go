@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultLocale is used whenever a message or hint has no translation
+const defaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// localeCatalog holds the translated messages and hints for one locale
+type localeCatalog struct {
+	Messages map[string]string        `json:"messages"`
+	Hints    map[string]localizedHint `json:"hints"`
+}
+
+// localizedHint overrides the text of a hint, keyed by Hint.ID
+type localizedHint struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Example     string   `json:"example,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+var (
+	langFlag      string
+	currentLocale = defaultLocale
+
+	catalogsOnce sync.Once
+	catalogs     map[string]*localeCatalog
+	catalogsErr  error
+)
+
+// formatVerbPattern matches fmt verbs such as %s, %d or %.1f but not %%
+var formatVerbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z]`)
+
+// loadCatalogs parses every embedded locale file once
+func loadCatalogs() (map[string]*localeCatalog, error) {
+	catalogsOnce.Do(func() {
+		catalogs = map[string]*localeCatalog{}
+		entries, err := localeFiles.ReadDir("locales")
+		if err != nil {
+			catalogsErr = err
+			return
+		}
+		for _, entry := range entries {
+			data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+			if err != nil {
+				catalogsErr = err
+				return
+			}
+			var catalog localeCatalog
+			if err := json.Unmarshal(data, &catalog); err != nil {
+				catalogsErr = fmt.Errorf("locale %s: %w", entry.Name(), err)
+				return
+			}
+			catalogs[strings.TrimSuffix(entry.Name(), ".json")] = &catalog
+		}
+	})
+	return catalogs, catalogsErr
+}
+
+// availableLocales returns the sorted list of bundled locales
+func availableLocales() []string {
+	loaded, _ := loadCatalogs()
+	locales := make([]string, 0, len(loaded))
+	for locale := range loaded {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// T returns the message for key in the current locale, falling back to English
+// and finally to the key itself. Arguments are applied with fmt.Sprintf.
+func T(key string, args ...interface{}) string {
+	message := lookupMessage(currentLocale, key)
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+func lookupMessage(locale, key string) string {
+	loaded, _ := loadCatalogs()
+	if catalog, ok := loaded[locale]; ok {
+		if message, ok := catalog.Messages[key]; ok && message != "" {
+			return message
+		}
+	}
+	if catalog, ok := loaded[defaultLocale]; ok {
+		if message, ok := catalog.Messages[key]; ok {
+			return message
+		}
+	}
+	return key
+}
+
+// localizeHint returns a copy of hint translated into the current locale.
+// Missing fields keep their English text; localized tags are added to the English ones.
+func localizeHint(hint Hint) Hint {
+	loaded, _ := loadCatalogs()
+	catalog, ok := loaded[currentLocale]
+	if !ok || currentLocale == defaultLocale {
+		return hint
+	}
+	translation, ok := catalog.Hints[hint.ID]
+	if !ok {
+		return hint
+	}
+
+	if translation.Title != "" {
+		hint.Title = translation.Title
+	}
+	if translation.Description != "" {
+		hint.Description = translation.Description
+	}
+	if translation.Example != "" {
+		hint.Example = translation.Example
+	}
+	if len(translation.Tags) > 0 {
+		hint.Tags = append(append([]string{}, hint.Tags...), translation.Tags...)
+	}
+	return hint
+}
+
+// localizedHints returns the hints of a category in the current locale
+func localizedHints(category string) []Hint {
+	hints := powerShellHints[category]
+	localized := make([]Hint, len(hints))
+	for i, hint := range hints {
+		localized[i] = localizeHint(hint)
+	}
+	return localized
+}
+
+// setLocale selects the locale used by T. The --lang flag wins over LC_ALL,
+// LC_MESSAGES and LANG; unknown languages fall back to English.
+func setLocale(flag string) {
+	currentLocale = detectLocale(flag, os.Getenv)
+
+	if flag != "" && normalizeLocale(flag) != "" && resolveLocale(normalizeLocale(flag)) == "" {
+		fmt.Fprintf(os.Stderr, "⚠️  Language '%s' is not available, falling back to %s. Available: %s\n",
+			flag, currentLocale, strings.Join(availableLocales(), ", "))
+	}
+}
+
+// detectLocale resolves the locale from the flag and the standard locale environment variables
+func detectLocale(flag string, getenv func(string) string) string {
+	candidates := []string{flag, getenv("LC_ALL"), getenv("LC_MESSAGES"), getenv("LANG")}
+	for _, candidate := range candidates {
+		tag := normalizeLocale(candidate)
+		if tag == "" {
+			continue
+		}
+		if locale := resolveLocale(tag); locale != "" {
+			return locale
+		}
+	}
+	return defaultLocale
+}
+
+// normalizeLocale turns values like "pt_BR.UTF-8" into "pt-br"; C and POSIX mean "not set"
+func normalizeLocale(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.ToLower(strings.ReplaceAll(value, "_", "-"))
+	if value == "c" || value == "posix" {
+		return ""
+	}
+	return value
+}
+
+// resolveLocale returns the bundled locale for a tag, trying the full tag then the language
+func resolveLocale(tag string) string {
+	loaded, _ := loadCatalogs()
+	if _, ok := loaded[tag]; ok {
+		return tag
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	if _, ok := loaded[language]; ok {
+		return language
+	}
+	return ""
+}
+
+// TranslationReport lists the problems found in one locale's catalog
+type TranslationReport struct {
+	Locale          string
+	MissingMessages []string
+	MissingHints    []string
+	UnknownMessages []string
+	MismatchedArgs  []string
+}
+
+// Complete reports whether the locale has nothing missing or inconsistent
+func (r TranslationReport) Complete() bool {
+	return len(r.MissingMessages) == 0 && len(r.MissingHints) == 0 &&
+		len(r.UnknownMessages) == 0 && len(r.MismatchedArgs) == 0
+}
+
+// checkTranslations compares a locale against the English catalog and hint set
+func checkTranslations(locale string) (TranslationReport, error) {
+	report := TranslationReport{Locale: locale}
+
+	loaded, err := loadCatalogs()
+	if err != nil {
+		return report, err
+	}
+	english, ok := loaded[defaultLocale]
+	if !ok {
+		return report, fmt.Errorf("missing %s catalog", defaultLocale)
+	}
+	catalog, ok := loaded[locale]
+	if !ok {
+		return report, fmt.Errorf("unknown locale %q", locale)
+	}
+
+	for key, message := range english.Messages {
+		translated, ok := catalog.Messages[key]
+		if !ok || translated == "" {
+			report.MissingMessages = append(report.MissingMessages, key)
+			continue
+		}
+		if !sameFormatVerbs(message, translated) {
+			report.MismatchedArgs = append(report.MismatchedArgs, key)
+		}
+	}
+	for key := range catalog.Messages {
+		if _, ok := english.Messages[key]; !ok {
+			report.UnknownMessages = append(report.UnknownMessages, key)
+		}
+	}
+
+	if locale != defaultLocale {
+		for _, category := range hintCategories {
+			for _, hint := range powerShellHints[category] {
+				if _, ok := catalog.Hints[hint.ID]; !ok {
+					report.MissingHints = append(report.MissingHints, hint.ID)
+				}
+			}
+		}
+	}
+
+	sort.Strings(report.MissingMessages)
+	sort.Strings(report.MissingHints)
+	sort.Strings(report.UnknownMessages)
+	sort.Strings(report.MismatchedArgs)
+	return report, nil
+}
+
+// sameFormatVerbs reports whether two messages use the same fmt verbs in the same order
+func sameFormatVerbs(a, b string) bool {
+	strip := func(s string) []string {
+		return formatVerbPattern.FindAllString(strings.ReplaceAll(s, "%%", ""), -1)
+	}
+	va, vb := strip(a), strip(b)
+	if len(va) != len(vb) {
+		return false
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return false
+		}
+	}
+	return true
+}
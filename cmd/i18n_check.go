@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var i18nCmd = &cobra.Command{
+	Use:   "i18n",
+	Short: "Manage translations of CLI messages and hints",
+	Long:  `Tools for translators working on the message catalogs and translated hint files`,
+}
+
+var i18nCheckCmd = &cobra.Command{
+	Use:   "check [language...]",
+	Short: "Report missing or inconsistent translations",
+	Long: `Compare each translation against the English catalog and report missing messages,
+untranslated hints, unknown keys and messages whose format arguments differ.
+
+Checks every bundled language unless specific languages are given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return checkTranslationCatalogs(args)
+	},
+}
+
+// checkTranslationCatalogs prints the translation problems of each locale and fails when
+// a locale is unknown or incomplete
+func checkTranslationCatalogs(locales []string) error {
+	fmt.Println(T("i18n.title"))
+	fmt.Println("=============================================")
+
+	if len(locales) == 0 {
+		for _, locale := range availableLocales() {
+			if locale != defaultLocale {
+				locales = append(locales, locale)
+			}
+		}
+	}
+
+	var unknown, incomplete []string
+	for _, locale := range locales {
+		report, err := checkTranslations(locale)
+		if err != nil {
+			fmt.Println(T("i18n.unknown_locale", locale, strings.Join(availableLocales(), ", ")))
+			unknown = append(unknown, locale)
+			continue
+		}
+
+		if report.Complete() {
+			fmt.Println(T("i18n.complete", locale))
+			continue
+		}
+		printTranslationProblems(T("i18n.missing_messages", locale, len(report.MissingMessages)), report.MissingMessages)
+		printTranslationProblems(T("i18n.missing_hints", locale, len(report.MissingHints)), report.MissingHints)
+		printTranslationProblems(T("i18n.unknown_messages", locale, len(report.UnknownMessages)), report.UnknownMessages)
+		printTranslationProblems(T("i18n.mismatched_args", locale, len(report.MismatchedArgs)), report.MismatchedArgs)
+		incomplete = append(incomplete, locale)
+	}
+
+	fmt.Println()
+	fmt.Println(T("i18n.fallback_note"))
+
+	if len(unknown) > 0 {
+		return reportedError(exitUsage, "unknown languages: %s", strings.Join(unknown, ", "))
+	}
+	if len(incomplete) > 0 {
+		return reportedError(exitFailure, "incomplete translations: %s", strings.Join(incomplete, ", "))
+	}
+	return nil
+}
+
+func printTranslationProblems(heading string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	fmt.Println(heading)
+	for _, key := range keys {
+		fmt.Printf("   • %s\n", key)
+	}
+}
+
+func init() {
+	i18nCmd.AddCommand(i18nCheckCmd)
+	rootCmd.AddCommand(i18nCmd)
+}
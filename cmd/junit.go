@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JUnit XML report in the format understood by most CI systems: each validated file is a
+// test suite, each check on it a test case, and Pester tests are test cases of their file.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// pesterSuiteName holds Pester tests that cannot be attributed to a validated file
+const pesterSuiteName = "Pester"
+
+// writeJUnit writes the validation report as JUnit XML
+func writeJUnit(w io.Writer, report ValidationReport) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(buildJUnit(report)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// buildJUnit converts the report into test suites. A failed check becomes a failure, a check
+// that could not run an error, and warnings are listed in the passing test case's output.
+func buildJUnit(report ValidationReport) junitTestSuites {
+	suites := junitTestSuites{Name: "pwsh-skills validate"}
+	suiteIndex := map[string]int{}
+
+	for _, file := range report.Files {
+		suite := junitTestSuite{Name: file.File}
+		for _, check := range file.Checks {
+			suite.Cases = append(suite.Cases, junitCheckCase(file, check))
+		}
+		suiteIndex[file.File] = len(suites.Suites)
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if report.TestsRun {
+		pesterSuite := func(name string) *junitTestSuite {
+			i, ok := suiteIndex[name]
+			if !ok {
+				i = len(suites.Suites)
+				suiteIndex[name] = i
+				suites.Suites = append(suites.Suites, junitTestSuite{Name: name})
+			}
+			return &suites.Suites[i]
+		}
+
+		if report.TestsError != "" {
+			suite := pesterSuite(pesterSuiteName)
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "tests",
+				ClassName: pesterSuiteName,
+				Error:     &junitProblem{Message: report.TestsError, Type: "tests"},
+			})
+		}
+		for _, test := range report.Tests {
+			name := test.File
+			if _, ok := suiteIndex[name]; !ok || name == "" {
+				name = pesterSuiteName
+			}
+			suite := pesterSuite(name)
+			suite.Cases = append(suite.Cases, junitTestResultCase(name, test))
+		}
+	}
+
+	for i := range suites.Suites {
+		suite := &suites.Suites[i]
+		for _, c := range suite.Cases {
+			suite.Tests++
+			switch {
+			case c.Failure != nil:
+				suite.Failures++
+			case c.Error != nil:
+				suite.Errors++
+			case c.Skipped != nil:
+				suite.Skipped++
+			}
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+	}
+	return suites
+}
+
+// junitCheckCase converts one check on a file into a test case
+func junitCheckCase(file FileResult, check CheckResult) junitTestCase {
+	testCase := junitTestCase{Name: check.Name, ClassName: file.File}
+
+	var findings []string
+	for _, finding := range file.Findings {
+		if finding.Category == check.Name {
+			findings = append(findings, junitFindingLine(file.File, finding))
+		}
+	}
+	details := strings.Join(findings, "\n")
+
+	switch check.Status {
+	case checkFailed:
+		testCase.Failure = &junitProblem{Message: fmt.Sprintf("%d finding(s)", len(findings)), Type: check.Name, Text: details}
+	case checkError:
+		testCase.Error = &junitProblem{Message: check.Problem, Type: check.Name, Text: details}
+	case checkSkipped:
+		testCase.Skipped = &struct{}{}
+	default:
+		testCase.SystemOut = details
+		if check.Problem != "" {
+			testCase.SystemOut = strings.TrimSpace(check.Problem + "\n" + details)
+		}
+	}
+	return testCase
+}
+
+// junitFindingLine formats a finding as "file:line:column: severity: message (rule)"
+func junitFindingLine(file string, finding Finding) string {
+	location := file
+	if finding.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", file, finding.Line, finding.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", location, finding.Severity, finding.Message, finding.RuleID)
+}
+
+// junitTestResultCase converts a Pester test into a test case
+func junitTestResultCase(className string, test TestResult) junitTestCase {
+	name := strings.TrimSpace(test.Path + " " + test.Name)
+	if name == "" {
+		name = "tests"
+	}
+	testCase := junitTestCase{
+		Name:      name,
+		ClassName: className,
+		Time:      fmt.Sprintf("%.3f", test.DurationMs/1000),
+	}
+
+	switch {
+	case testFailed(test):
+		message := strings.TrimSpace(test.Message)
+		if i := strings.Index(message, "\n"); i >= 0 {
+			message = message[:i]
+		}
+		text := test.Message
+		if test.File != "" && test.Line > 0 {
+			text = fmt.Sprintf("%s\n%s:%d", strings.TrimSpace(text), test.File, test.Line)
+		}
+		testCase.Failure = &junitProblem{Message: strings.TrimSpace(message), Type: "tests", Text: text}
+	case !testPassed(test):
+		testCase.Skipped = &struct{}{}
+	}
+	return testCase
+}
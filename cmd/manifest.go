@@ -0,0 +1,423 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// powerShellExtensions are the extensions of the files validate checks: scripts, script
+// modules, module manifests and other data files, and formatting and type files
+var powerShellExtensions = []string{".ps1", ".psm1", ".psd1", ".ps1xml"}
+
+// manifestKeys mark a .psd1 file as a module manifest rather than another data file such as
+// PSScriptAnalyzerSettings.psd1
+var manifestKeys = []string{"ModuleVersion", "RootModule", "ModuleToProcess", "GUID", "FunctionsToExport"}
+
+// requiredManifestKeys are the keys Test-ModuleManifest and the PowerShell Gallery expect
+var requiredManifestKeys = []string{"ModuleVersion", "GUID", "Author", "Description"}
+
+// manifestFileKeys name files the module loads, relative to the manifest
+var manifestFileKeys = []string{"RootModule", "ModuleToProcess", "NestedModules", "ScriptsToProcess", "TypesToProcess", "FormatsToProcess"}
+
+// moduleFileExtensions tell a file in manifestFileKeys from a module name such as
+// Microsoft.PowerShell.Utility, which is looked up on the module path instead
+var moduleFileExtensions = []string{".ps1", ".psm1", ".psd1", ".dll", ".ps1xml", ".cdxml"}
+
+// manifestVersionKeys hold versions that must parse as a System.Version, such as 1.2.0
+var manifestVersionKeys = []string{"ModuleVersion", "PowerShellVersion"}
+
+// Patterns of valid versions and prerelease labels
+var (
+	manifestVersionPattern    = regexp.MustCompile(`^\d+(\.\d+){1,3}$`)
+	manifestPrereleasePattern = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+)
+
+// ModuleImport is the outcome of importing a module in a fresh PowerShell process
+type ModuleImport struct {
+	Imported  bool     `json:"imported"`
+	Errors    []string `json:"errors"`
+	Warnings  []string `json:"warnings"`
+	Functions []string `json:"functions"`
+}
+
+// isPowerShellFile reports whether validate checks the file, by its extension
+func isPowerShellFile(name string) bool {
+	return containsString(powerShellExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// fileChecks returns the checks run on a file, in order. Formatting and type files are XML
+// and only checked for well-formedness; module manifests and script modules are also
+// imported, except a .psm1 whose manifest sits next to it and is checked instead.
+func fileChecks(filename string) []string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ps1xml":
+		return []string{categorySyntax}
+	case ".psd1":
+		return []string{categorySyntax, categoryModule, categoryCrossPlatform, categoryBestPractices}
+	case ".psm1":
+		if _, err := os.Stat(stageFile(strings.TrimSuffix(filename, filepath.Ext(filename)) + ".psd1")); err != nil {
+			return []string{categorySyntax, categoryModule, categoryCrossPlatform, categoryBestPractices}
+		}
+	}
+	return []string{categorySyntax, categoryCrossPlatform, categoryBestPractices}
+}
+
+// isModuleManifest reports whether a parsed .psd1 file is a module manifest
+func isModuleManifest(tree *SyntaxTree) bool {
+	for _, key := range manifestKeys {
+		if _, ok := tree.manifestEntry(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestEntry returns the entry for key, ignoring case as PowerShell does
+func (t *SyntaxTree) manifestEntry(key string) (ManifestEntry, bool) {
+	for _, entry := range t.Manifest {
+		if strings.EqualFold(entry.Key, key) {
+			return entry, true
+		}
+	}
+	return ManifestEntry{}, false
+}
+
+// validateXML checks that a formatting or type file is well-formed XML
+func validateXML(out io.Writer, result *FileResult) bool {
+	data, err := os.ReadFile(sourceFile(result.File))
+	if err != nil {
+		fmt.Fprintln(out, "  "+T("validate.syntax_error", err))
+		result.addCheck(categorySyntax, checkError, err)
+		return false
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err = decoder.Token()
+		if err != nil {
+			break
+		}
+	}
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		finding := builtinRule("SY002").finding(result.File, Extent{Line: syntaxErr.Line, Column: 1}, T("validate.xml_invalid", syntaxErr.Msg))
+		printFinding(out, finding)
+		result.Findings = append(result.Findings, finding)
+		result.addCheck(categorySyntax, checkFailed, nil)
+		return false
+	}
+
+	fmt.Fprintln(out, "  "+T("validate.syntax_valid"))
+	result.addCheck(categorySyntax, checkPassed, nil)
+	return true
+}
+
+// checkModule checks a module manifest against the files of its module and imports the
+// module in a fresh PowerShell process. A .psm1 file without a manifest is only imported.
+func checkModule(out io.Writer, result *FileResult) {
+	var findings []Finding
+	var problem error
+	if result.tree.Manifest != nil {
+		findings, problem = checkManifest(result.tree)
+	}
+
+	// A manifest that points at missing files or has an invalid version cannot be imported,
+	// and importing it would only repeat those errors
+	if problem == nil && !blocksImport(findings) {
+		var importFindings []Finding
+		if problem = stageModule(result.tree); problem == nil {
+			importFindings, problem = checkModuleImport(result.File)
+		}
+		findings = append(findings, importFindings...)
+	}
+	findings = result.suppress(projectConfig.configure(findings))
+	sortFindings(findings)
+
+	status := checkStatus(findings)
+	if problem != nil {
+		fmt.Fprintln(out, "  "+T("validate.module_check_failed", problem))
+		status = checkError
+	}
+	if len(findings) > 0 {
+		fmt.Fprintln(out, "  "+T("validate.module_issues"))
+		for _, finding := range findings {
+			printFinding(out, finding)
+		}
+	} else if problem == nil {
+		fmt.Fprintln(out, "  "+T("validate.module_ok"))
+	}
+	result.Findings = append(result.Findings, findings...)
+	result.addCheck(categoryModule, status, problem)
+}
+
+// blocksImport reports whether the manifest findings already explain why the module cannot
+// be imported
+func blocksImport(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.RuleID == "MM002" || finding.RuleID == "MM005" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkManifest reports missing required keys, invalid versions, files the manifest refers
+// to that do not exist and differences between FunctionsToExport and the functions the
+// module defines
+func checkManifest(tree *SyntaxTree) ([]Finding, error) {
+	findings := []Finding{}
+	report := func(id string, extent Extent, message string) {
+		findings = append(findings, builtinRule(id).finding(tree.File, extent, message))
+	}
+
+	for _, key := range requiredManifestKeys {
+		if _, ok := tree.manifestEntry(key); !ok {
+			report("MM001", Extent{}, T("validate.manifest_missing_key", key))
+		}
+	}
+
+	for _, key := range manifestVersionKeys {
+		if entry, ok := tree.manifestEntry(key); ok && entry.Constant && !validVersion(entry.Values) {
+			report("MM005", entry.Extent, T("validate.manifest_bad_version", key, strings.Join(entry.Values, ", ")))
+		}
+	}
+	if entry, ok := tree.manifestEntry("PrivateData.PSData.Prerelease"); ok && entry.Constant {
+		if len(entry.Values) != 1 || !manifestPrereleasePattern.MatchString(entry.Values[0]) {
+			report("MM005", entry.Extent, T("validate.manifest_bad_prerelease", strings.Join(entry.Values, ", ")))
+		}
+	}
+
+	dir := filepath.Dir(tree.File)
+	for _, key := range manifestFileKeys {
+		entry, ok := tree.manifestEntry(key)
+		if !ok || !entry.Constant {
+			continue
+		}
+		for _, value := range entry.Values {
+			if !containsString(moduleFileExtensions, strings.ToLower(filepath.Ext(value))) {
+				continue
+			}
+			if _, err := os.Stat(stageFile(manifestPath(dir, value))); err != nil {
+				report("MM002", entry.Extent, T("validate.manifest_file_missing", key, value))
+			}
+		}
+	}
+
+	exports, ok := tree.manifestEntry("FunctionsToExport")
+	if !ok || !exports.Constant || hasWildcard(exports.Values) {
+		return findings, nil
+	}
+	defined, rootFunctions, err := moduleFunctions(tree)
+	if err != nil || defined == nil {
+		return findings, err
+	}
+	for _, name := range exports.Values {
+		if !defined[strings.ToLower(name)] {
+			report("MM003", exports.Extent, T("validate.manifest_export_undefined", name))
+		}
+	}
+	for _, function := range rootFunctions {
+		if !containsRule(exports.Values, function) {
+			report("MM004", exports.Extent, T("validate.manifest_not_exported", function, rootModule(tree)))
+		}
+	}
+	return findings, nil
+}
+
+// validVersion reports whether a version key holds a single System.Version such as 1.2.0
+func validVersion(values []string) bool {
+	return len(values) == 1 && manifestVersionPattern.MatchString(values[0])
+}
+
+// hasWildcard reports whether any value is a wildcard pattern, which cannot be compared
+// with the functions a module defines
+func hasWildcard(values []string) bool {
+	for _, value := range values {
+		if strings.ContainsAny(value, "*?[") {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestPath resolves a path from a manifest, written with either separator, against the
+// manifest's directory
+func manifestPath(dir, value string) string {
+	return filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(value, `\`, "/")))
+}
+
+// rootModule returns the script module a manifest loads, or "" when it loads none or a
+// binary module
+func rootModule(tree *SyntaxTree) string {
+	for _, key := range []string{"RootModule", "ModuleToProcess"} {
+		entry, ok := tree.manifestEntry(key)
+		if !ok || !entry.Constant || len(entry.Values) != 1 {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Values[0])) {
+		case ".psm1", ".ps1":
+			return entry.Values[0]
+		}
+	}
+	return ""
+}
+
+// moduleFunctions returns the names, lowercased, of the top-level functions defined by the
+// module's scripts, and the functions the root module itself defines. The names are nil for
+// a module without scripts, such as a binary module.
+func moduleFunctions(tree *SyntaxTree) (map[string]bool, []string, error) {
+	files, trees, root, err := moduleScripts(tree, parsePowerShellFiles)
+	if err != nil || len(files) == 0 {
+		return nil, nil, err
+	}
+	defined := map[string]bool{}
+	var rootFunctions []string
+	for _, file := range files {
+		for _, function := range trees[file].Functions {
+			if function.Nested {
+				continue
+			}
+			defined[strings.ToLower(function.Name)] = true
+			if file == root {
+				rootFunctions = append(rootFunctions, function.Name)
+			}
+		}
+	}
+	return defined, rootFunctions, nil
+}
+
+// moduleScripts returns the scripts a module is made of, parsed with parse: its root module,
+// its script nested modules and the scripts they dot-source. A script dot-sourced through a
+// path only known at run time, as in a loop over Get-ChildItem, adds the scripts validate
+// would find in the module's directory, since any of them may be loaded. Root is "" when
+// the module has no script root module; a .psm1 without a manifest is its own root.
+func moduleScripts(tree *SyntaxTree, parse func([]string) (map[string]*SyntaxTree, error)) (files []string, trees map[string]*SyntaxTree, root string, err error) {
+	dir := filepath.Dir(tree.File)
+	var queue []string
+	if strings.EqualFold(filepath.Ext(tree.File), ".psm1") {
+		root = tree.File
+		queue = append(queue, root)
+	} else {
+		if name := rootModule(tree); name != "" {
+			root = manifestPath(dir, name)
+			queue = append(queue, root)
+		}
+		if entry, ok := tree.manifestEntry("NestedModules"); ok && entry.Constant {
+			for _, value := range entry.Values {
+				switch strings.ToLower(filepath.Ext(value)) {
+				case ".ps1", ".psm1":
+					queue = append(queue, manifestPath(dir, value))
+				}
+			}
+		}
+	}
+
+	trees = map[string]*SyntaxTree{}
+	dynamic, searched := false, false
+	for len(queue) > 0 || (dynamic && !searched) {
+		if len(queue) == 0 {
+			searched = true
+			found, _ := newFileDiscovery().scan(dir)
+			for _, file := range found {
+				name := strings.ToLower(file)
+				if strings.HasSuffix(name, ".ps1") && !strings.HasSuffix(name, ".tests.ps1") {
+					queue = append(queue, file)
+				}
+			}
+			continue
+		}
+
+		var batch []string
+		for _, file := range queue {
+			if _, seen := trees[file]; seen || containsString(batch, file) {
+				continue
+			}
+			if _, err := os.Stat(stageFile(file)); err == nil {
+				batch = append(batch, file)
+			}
+		}
+		queue = nil
+		if len(batch) == 0 {
+			continue
+		}
+		parsed, err := parse(batch)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		for _, file := range batch {
+			script := parsed[file]
+			if script == nil {
+				script = &SyntaxTree{File: file}
+			}
+			files = append(files, file)
+			trees[file] = script
+			for _, dotSource := range script.DotSources {
+				if dotSource.Path == "" {
+					dynamic = true
+				} else {
+					queue = append(queue, manifestPath(filepath.Dir(file), dotSource.Path))
+				}
+			}
+		}
+	}
+	return files, trees, root, nil
+}
+
+// stageModule writes the staged or committed content of the files a module loads beside the
+// copy of its manifest or script module, so that with --staged or --commits importing it
+// loads what will be committed or pushed
+func stageModule(tree *SyntaxTree) error {
+	if stagedDir == "" {
+		return nil
+	}
+	dir := filepath.Dir(tree.File)
+	for _, key := range append([]string{"RequiredAssemblies", "FileList"}, manifestFileKeys...) {
+		if entry, ok := tree.manifestEntry(key); ok && entry.Constant {
+			for _, value := range entry.Values {
+				stageFile(manifestPath(dir, value))
+			}
+		}
+	}
+	_, _, _, err := moduleScripts(tree, parsePowerShellFiles)
+	return err
+}
+
+// checkModuleImport imports the module in a fresh PowerShell process and reports the errors
+// and warnings importing it raised
+func checkModuleImport(filename string) ([]Finding, error) {
+	return checkModuleImportWith(powerShellWorkerCommand, filename)
+}
+
+// checkModuleImportWith imports the module in a fresh process started by command
+func checkModuleImportWith(command func() (*exec.Cmd, error), filename string) ([]Finding, error) {
+	path, err := filepath.Abs(sourceFile(filename))
+	if err != nil {
+		return nil, err
+	}
+	output, err := runIsolatedScript(command, "import-module.ps1", scriptParams{"Path": path}, powerShellTimeout)
+	if err != nil {
+		return nil, err
+	}
+	var imported ModuleImport
+	if err := json.Unmarshal(output, &imported); err != nil {
+		return nil, fmt.Errorf("unexpected import output: %s", strings.TrimSpace(string(output)))
+	}
+
+	findings := []Finding{}
+	for _, message := range imported.Errors {
+		findings = append(findings, builtinRule("MM006").finding(filename, Extent{}, T("validate.module_import_failed", message)))
+	}
+	for _, message := range imported.Warnings {
+		findings = append(findings, builtinRule("MM007").finding(filename, Extent{}, T("validate.module_import_warning", message)))
+	}
+	return findings, nil
+}
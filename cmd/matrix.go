@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// matrixIcons are shown in the compatibility matrix for each status. Every icon is two
+// columns wide so the columns line up.
+var matrixIcons = map[string]string{
+	checkPassed:  "✅",
+	checkWarning: "⚠️",
+	checkFailed:  "❌",
+	checkError:   "💥",
+	checkSkipped: "➖",
+}
+
+// PowerShellHost is one PowerShell executable of the compatibility matrix. Name labels it
+// by executable and version, such as "pwsh 7.4.6".
+type PowerShellHost struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// MatrixResult is how the syntax and import checks of one file went with one executable
+type MatrixResult struct {
+	File       string    `json:"file"`
+	PowerShell string    `json:"powershell"`
+	Status     string    `json:"status"`
+	Findings   []Finding `json:"findings"`
+	Problem    string    `json:"problem,omitempty"`
+}
+
+// CompatibilityMatrix holds the results of every file with every configured executable
+type CompatibilityMatrix struct {
+	PowerShell []PowerShellHost `json:"powershell"`
+	Results    []MatrixResult   `json:"results"`
+}
+
+// runCompatibilityMatrix runs the syntax and import checks of the files with each configured
+// executable, one executable at a time per worker so their processes never mix
+func runCompatibilityMatrix(files []string) *CompatibilityMatrix {
+	hosts := make([]PowerShellHost, len(powerShellExecutables))
+	results := make([][]MatrixResult, len(powerShellExecutables))
+	var wg sync.WaitGroup
+	for i, executable := range powerShellExecutables {
+		wg.Add(1)
+		go func(i int, executable string) {
+			defer wg.Done()
+			hosts[i], results[i] = checkWithExecutable(executable, files)
+		}(i, executable)
+	}
+	wg.Wait()
+
+	// Executables sharing a name, such as two installed pwsh versions, are told apart by
+	// their version, or by their path when the version is the same too
+	labels := map[string]int{}
+	for _, host := range hosts {
+		labels[host.Name]++
+	}
+	for i := range hosts {
+		if labels[hosts[i].Name] > 1 {
+			hosts[i].Name = hosts[i].Path
+		}
+		for j := range results[i] {
+			results[i][j].PowerShell = hosts[i].Name
+		}
+	}
+
+	matrix := &CompatibilityMatrix{PowerShell: hosts, Results: []MatrixResult{}}
+	for _, file := range files {
+		for i := range hosts {
+			for _, result := range results[i] {
+				if result.File == file {
+					matrix.Results = append(matrix.Results, result)
+				}
+			}
+		}
+	}
+	return matrix
+}
+
+// checkWithExecutable parses every file and imports every module with one executable
+func checkWithExecutable(executable string, files []string) (PowerShellHost, []MatrixResult) {
+	command := powerShellWorkerCommandFor(executable)
+	pool := newPowerShellPool(1, command)
+	defer pool.stop()
+
+	name := strings.TrimSuffix(filepath.Base(executable), filepath.Ext(executable))
+	host := PowerShellHost{Name: name, Path: executable}
+	results := make([]MatrixResult, len(files))
+	for i, file := range files {
+		results[i] = MatrixResult{File: file, Status: checkSkipped, Findings: []Finding{}}
+	}
+
+	version, err := executableVersion(executable, pool)
+	if err == nil {
+		host.Version = version
+		host.Name = name + " " + version
+	}
+	var parseable []string
+	for _, file := range files {
+		if !strings.EqualFold(filepath.Ext(file), ".ps1xml") {
+			parseable = append(parseable, file)
+		}
+	}
+	var trees map[string]*SyntaxTree
+	if err == nil && len(parseable) > 0 {
+		trees, err = parsePowerShellFilesIn(pool, parseable)
+	}
+
+	for i, file := range files {
+		result := &results[i]
+		if strings.EqualFold(filepath.Ext(file), ".ps1xml") {
+			continue
+		}
+		if err != nil {
+			result.Status, result.Problem = checkError, err.Error()
+			continue
+		}
+		tree := trees[file]
+		if tree == nil {
+			result.Status, result.Problem = checkError, fmt.Sprintf("no parser output for %s", file)
+			continue
+		}
+		if len(tree.Diagnostics) > 0 {
+			for _, diagnostic := range tree.Diagnostics {
+				result.Findings = append(result.Findings, syntaxFinding(diagnostic))
+			}
+			result.Status = checkFailed
+			continue
+		}
+
+		result.Status = checkPassed
+		if containsString(fileChecks(file), categoryModule) && (strings.EqualFold(filepath.Ext(file), ".psm1") || isModuleManifest(tree)) {
+			var findings []Finding
+			importErr := stageModule(tree)
+			if importErr == nil {
+				findings, importErr = checkModuleImportWith(command, file)
+			}
+			if importErr != nil {
+				result.Status, result.Problem = checkError, importErr.Error()
+				continue
+			}
+			result.Findings = matrixFindings(tree, findings)
+			result.Status = checkStatus(result.Findings)
+		}
+	}
+	return host, results
+}
+
+// matrixFindings applies the project's rule settings and the file's suppressions to the
+// findings of importing a module, as the main report does
+func matrixFindings(tree *SyntaxTree, findings []Finding) []Finding {
+	result := &FileResult{File: tree.File, suppressions: findSuppressions(tree)}
+	return result.suppress(projectConfig.configure(findings))
+}
+
+// result returns the result of a file with an executable
+func (m *CompatibilityMatrix) result(file, powerShell string) (MatrixResult, bool) {
+	for _, result := range m.Results {
+		if result.File == file && result.PowerShell == powerShell {
+			return result, true
+		}
+	}
+	return MatrixResult{}, false
+}
+
+// failedFiles returns the files that fail or could not be checked with any executable
+func (m *CompatibilityMatrix) failedFiles() []string {
+	var files []string
+	for _, result := range m.Results {
+		if (result.Status == checkFailed || result.Status == checkError) && !containsString(files, result.File) {
+			files = append(files, result.File)
+		}
+	}
+	return files
+}
+
+// printCompatibilityMatrix prints a table of files by executable, followed by what went
+// wrong for each file that did not pass everywhere
+func printCompatibilityMatrix(out io.Writer, matrix *CompatibilityMatrix, files []string) {
+	fmt.Fprintln(out, T("validate.matrix_title"))
+	fmt.Fprintln(out, "=============================================")
+
+	header := T("validate.matrix_file")
+	width := utf8.RuneCountInString(header)
+	for _, file := range files {
+		width = max(width, utf8.RuneCountInString(file))
+	}
+	pad := func(text string, size int) string {
+		return text + strings.Repeat(" ", max(size-utf8.RuneCountInString(text), 0))
+	}
+
+	line := pad(header, width)
+	for _, host := range matrix.PowerShell {
+		line += "  " + host.Name
+	}
+	fmt.Fprintln(out, strings.TrimRight(line, " "))
+	for _, file := range files {
+		line := pad(file, width)
+		for _, host := range matrix.PowerShell {
+			result, _ := matrix.result(file, host.Name)
+			// The icons are two columns wide however many runes they take
+			line += "  " + matrixIcons[result.Status] + strings.Repeat(" ", max(utf8.RuneCountInString(host.Name)-2, 0))
+		}
+		fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+
+	for _, result := range matrix.Results {
+		if result.Status == checkPassed || result.Status == checkSkipped {
+			continue
+		}
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, T("validate.matrix_issues", matrixIcons[result.Status], result.File, result.PowerShell))
+		if result.Problem != "" {
+			fmt.Fprintln(out, "     • "+T("validate.matrix_problem", result.Problem))
+		}
+		for _, finding := range result.Findings {
+			printFinding(out, finding)
+		}
+	}
+	fmt.Fprintln(out)
+	if failed := len(matrix.failedFiles()); failed > 0 {
+		fmt.Fprintln(out, T("validate.matrix_failed", failed, len(matrix.PowerShell)))
+	} else {
+		fmt.Fprintln(out, T("validate.matrix_passed", len(matrix.PowerShell)))
+	}
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// testTimeout bounds a whole Pester run
+var testTimeout = 10 * time.Minute
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run your Pester tests",
+	Long: `Discover *.Tests.ps1 files in the current directory and run them with Pester.
+
+Each test is listed with its result and duration, failures with their message and
+location. The command fails when any test fails. Tests run in a fresh PowerShell
+process, so state left behind by one run never leaks into the next.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := useProjectConfig(); err != nil {
+			return err
+		}
+
+		fmt.Println(T("test.title"))
+		fmt.Println("=============================================")
+
+		if !isPowerShellAvailable() {
+			fmt.Println(T("validate.pwsh_missing"))
+			fmt.Println("   " + T("validate.pwsh_install"))
+			return reported(errPowerShellNotFound)
+		}
+		_, err := runTests(os.Stdout)
+		return reported(err)
+	},
+}
+
+// TestResult is the outcome of one Pester test
+type TestResult struct {
+	Name       string  `json:"name"`
+	Path       string  `json:"path"`
+	File       string  `json:"file"`
+	Line       int     `json:"line"`
+	Result     string  `json:"result"`
+	DurationMs float64 `json:"durationMs"`
+	Message    string  `json:"message"`
+}
+
+// TestRun is the result of running Pester over a set of test files
+type TestRun struct {
+	Available bool         `json:"available"`
+	Version   string       `json:"version"`
+	Tests     []TestResult `json:"tests"`
+}
+
+// TestSummary counts the tests of a run by outcome
+type TestSummary struct {
+	Total    int
+	Passed   int
+	Failed   int
+	Skipped  int
+	Duration time.Duration
+}
+
+// runTests discovers and runs the Pester tests, printing the results to out. It returns an
+// error when tests fail or cannot be run, along with the results of the tests that ran;
+// failing tests are an exitFailure and a missing or broken Pester an exitEnvironment.
+func runTests(out io.Writer) ([]TestResult, error) {
+	files := findTestFiles()
+	if len(files) == 0 {
+		fmt.Fprintln(out, T("test.no_files"))
+		return nil, nil
+	}
+	fmt.Fprintln(out, T("test.found_files", len(files)))
+
+	run, err := runPesterTests(files)
+	if err != nil {
+		fmt.Fprintln(out, T("test.run_error", err))
+		return nil, exitError(exitEnvironment, fmt.Errorf("could not run tests: %w", err))
+	}
+	if !run.Available {
+		fmt.Fprintln(out, T("test.pester_missing"))
+		fmt.Fprintln(out, "   "+T("test.pester_install"))
+		return nil, exitError(exitEnvironment, fmt.Errorf("Pester is not installed"))
+	}
+	fmt.Fprintln(out, T("test.pester_detected", run.Version))
+	fmt.Fprintln(out)
+
+	summary := printTestResults(out, run.Tests)
+	if summary.Failed > 0 {
+		return run.Tests, exitError(exitFailure, fmt.Errorf("%d of %d tests failed", summary.Failed, summary.Total))
+	}
+	return run.Tests, nil
+}
+
+// findTestFiles returns the Pester test files among the PowerShell files
+func findTestFiles() []string {
+	var files []string
+	for _, file := range findPowerShellFiles() {
+		if strings.HasSuffix(strings.ToLower(file), ".tests.ps1") {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// runPesterTests runs Pester in a fresh PowerShell process and returns the test results
+// with file paths made relative to the current directory
+func runPesterTests(files []string) (*TestRun, error) {
+	paths, err := scriptPathArgs(files)
+	if err != nil {
+		return nil, err
+	}
+	output, err := runPowerShellScriptIsolated("pester.ps1", scriptParams{"Path": paths}, testTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var run TestRun
+	if err := json.Unmarshal(output, &run); err != nil {
+		return nil, fmt.Errorf("unexpected Pester output: %w", err)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		for i, test := range run.Tests {
+			if rel, err := filepath.Rel(wd, test.File); err == nil && filepath.IsAbs(test.File) {
+				run.Tests[i].File = rel
+			}
+		}
+	}
+	return &run, nil
+}
+
+// printTestResults prints each test grouped by file with its outcome, duration and failure
+// message, followed by a summary
+func printTestResults(out io.Writer, tests []TestResult) TestSummary {
+	var summary TestSummary
+	currentFile := ""
+	for _, test := range tests {
+		if test.File != "" && test.File != currentFile {
+			currentFile = test.File
+			fmt.Fprintf(out, "📄 %s\n", test.File)
+		}
+
+		name := strings.TrimSpace(test.Path + " " + test.Name)
+		if name == "" {
+			name = T("test.file_failed")
+		}
+		duration := time.Duration(test.DurationMs * float64(time.Millisecond))
+		summary.Total++
+		summary.Duration += duration
+
+		switch strings.ToLower(test.Result) {
+		case "passed":
+			summary.Passed++
+			fmt.Fprintf(out, "  ✅ %s (%s)\n", name, formatTestDuration(duration))
+		case "failed":
+			summary.Failed++
+			fmt.Fprintf(out, "  ❌ %s (%s)\n", name, formatTestDuration(duration))
+			for _, line := range strings.Split(strings.TrimSpace(test.Message), "\n") {
+				fmt.Fprintf(out, "     %s\n", strings.TrimRight(line, "\r"))
+			}
+			if test.File != "" && test.Line > 0 {
+				fmt.Fprintf(out, "     --> %s:%d\n", test.File, test.Line)
+			}
+		default:
+			summary.Skipped++
+			fmt.Fprintf(out, "  ⏭️  %s (%s)\n", name, T("test.skipped"))
+		}
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, T("test.summary", summary.Total, summary.Passed, summary.Failed, summary.Skipped, formatTestDuration(summary.Duration)))
+	return summary
+}
+
+// testFailed reports whether a test failed
+func testFailed(test TestResult) bool {
+	return strings.EqualFold(test.Result, "failed")
+}
+
+// testPassed reports whether a test passed; tests that neither passed nor failed were skipped
+func testPassed(test TestResult) bool {
+	return strings.EqualFold(test.Result, "passed")
+}
+
+// formatTestDuration shows short durations in milliseconds and longer ones in seconds
+func formatTestDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%d ms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.2f s", d.Seconds())
+}
+
+func init() {
+	testCmd.Flags().DurationVar(&testTimeout, "timeout", testTimeout, "How long the whole test run may take")
+	rootCmd.AddCommand(testCmd)
+}
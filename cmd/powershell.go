@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// errPowerShellNotFound is returned when neither pwsh nor powershell is on the PATH
+var errPowerShellNotFound = errors.New("PowerShell not found")
+
+// powerShellScripts are the helper scripts run through PowerShell. Every value a script
+// works on (file paths, command names) is passed as an argument, never formatted into
+// script text, so quotes, $ or ; in a filename cannot change what PowerShell executes.
+//
+//go:embed pwsh/*.ps1
+var powerShellScripts embed.FS
+
+// powerShellExecutables are the executables configured with --powershell or the powershell
+// setting of .pwsh-skills.yml. The first one runs every check; the others only run the
+// syntax and import checks of the compatibility matrix.
+var powerShellExecutables []string
+
+// powerShellExecutable returns the PowerShell executable to use: the first configured one, or
+// else pwsh (PowerShell 7+) or powershell, whichever is found first
+func powerShellExecutable() (string, error) {
+	if len(powerShellExecutables) > 0 {
+		return powerShellExecutables[0], nil
+	}
+	for _, name := range []string{"pwsh", "powershell"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", errPowerShellNotFound
+}
+
+// resolvePowerShellExecutables finds the configured executables, given as names on the PATH
+// or as paths, which are relative to dir when not absolute
+func resolvePowerShellExecutables(names []string, dir string) ([]string, error) {
+	var executables []string
+	for _, name := range names {
+		candidate := name
+		if strings.ContainsAny(name, `/\`) && !filepath.IsAbs(name) {
+			candidate = filepath.Join(dir, name)
+		}
+		path, err := exec.LookPath(candidate)
+		if err != nil {
+			return nil, exitError(exitEnvironment, fmt.Errorf("PowerShell executable %q not found", name))
+		}
+		if !containsString(executables, path) {
+			executables = append(executables, path)
+		}
+	}
+	return executables, nil
+}
+
+// powerShellScriptCommand builds the command line for running a script file with args.
+// Nothing is formatted into the command, so args reach the script exactly as given.
+func powerShellScriptCommand(executable, script string, args ...string) *exec.Cmd {
+	commandArgs := []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", script}
+	commandArgs = append(commandArgs, args...)
+	return exec.Command(executable, commandArgs...)
+}
+
+// scriptPathArgs converts file names into absolute paths for runPowerShellScript, so a
+// file named like "-Force.ps1" can never be mistaken for a parameter name. With --staged,
+// the paths are those of the staged content.
+func scriptPathArgs(files []string) ([]string, error) {
+	paths := make([]string, len(files))
+	for i, file := range files {
+		abs, err := filepath.Abs(sourceFile(file))
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = abs
+	}
+	return paths, nil
+}
+
+// materializeScript writes an embedded script to the cache directory so it can be run
+// with -File. The file name includes a content hash, so upgrades never run a stale copy.
+func materializeScript(name string) (string, error) {
+	data, err := powerShellScripts.ReadFile(path.Join("pwsh", name))
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		dir = filepath.Join(os.TempDir(), appDirName)
+	}
+	sum := sha256.Sum256(data)
+	target := filepath.Join(dir, "scripts", strings.TrimSuffix(name, ".ps1")+"-"+hex.EncodeToString(sum[:6])+".ps1")
+
+	if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, data) {
+		return target, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file and rename so concurrent runs never see a partial script
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+name+"-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return target, nil
+}
+
+// powerShellVersion returns the version of the PowerShell executable, cached by path and
+// modification time so it is only queried again after PowerShell is upgraded
+func powerShellVersion() (string, error) {
+	executable, err := powerShellExecutable()
+	if err != nil {
+		return "", err
+	}
+	return executableVersion(executable, sharedPowerShell)
+}
+
+// executableVersion returns the version of a PowerShell executable, asking the workers of
+// pool when it is not cached
+func executableVersion(executable string, pool *powerShellPool) (string, error) {
+	info, err := os.Stat(executable)
+	if err != nil {
+		return "", err
+	}
+
+	type versionEntry struct {
+		ModTime int64  `json:"mod_time"`
+		Version string `json:"version"`
+	}
+	cacheFile := ""
+	versions := map[string]versionEntry{}
+	if dir, err := cacheDir(); err == nil {
+		cacheFile = filepath.Join(dir, "powershell-versions.json")
+		if data, err := os.ReadFile(cacheFile); err == nil {
+			_ = json.Unmarshal(data, &versions)
+		}
+	}
+	if entry, ok := versions[executable]; ok && entry.ModTime == info.ModTime().Unix() && entry.Version != "" {
+		return entry.Version, nil
+	}
+
+	output, err := pool.run("version.ps1", nil)
+	if err != nil {
+		return "", err
+	}
+	version := strings.TrimSpace(string(output))
+
+	if cacheFile != "" {
+		versions[executable] = versionEntry{ModTime: info.ModTime().Unix(), Version: version}
+		if data, err := json.MarshalIndent(versions, "", "  "); err == nil {
+			_ = writeFileWithDirs(cacheFile, data)
+		}
+	}
+	return version, nil
+}
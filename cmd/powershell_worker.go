@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// powerShellTimeout bounds PowerShell startup and each request to the worker
+var powerShellTimeout = 60 * time.Second
+
+// errWorkerExited is returned when the worker process exits while handling a request
+var errWorkerExited = errors.New("PowerShell worker exited unexpectedly")
+
+// scriptParams are the named parameters passed to a helper script
+type scriptParams map[string]interface{}
+
+// workerRequest is one JSON line sent to pwsh/worker.ps1
+type workerRequest struct {
+	ID         int          `json:"id"`
+	Op         string       `json:"op,omitempty"`
+	Script     string       `json:"script,omitempty"`
+	Parameters scriptParams `json:"parameters,omitempty"`
+}
+
+// workerResponse is one JSON line received from pwsh/worker.ps1
+type workerResponse struct {
+	ID     int    `json:"id"`
+	Ready  bool   `json:"ready"`
+	Output string `json:"output"`
+	Error  string `json:"error"`
+}
+
+// powerShellWorker runs helper scripts in a long-lived PowerShell process, starting it on
+// first use and again after it crashes or times out. Requests are handled one at a time.
+type powerShellWorker struct {
+	mu      sync.Mutex
+	command func() (*exec.Cmd, error)
+	timeout time.Duration
+	process *workerProcess
+	nextID  int
+}
+
+// workerProcess is one running worker and the stream of responses read from its stdout
+type workerProcess struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	responses chan workerResponse
+	exited    chan struct{}
+	stderr    *lockedBuffer
+}
+
+// lockedBuffer collects stderr while the process runs and can be read at any time
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.TrimSpace(b.buf.String())
+}
+
+// powerShellPool hands out workers to concurrent callers, starting up to size of them as
+// demand requires and reusing the most recently used idle worker first
+type powerShellPool struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	size    int
+	command func() (*exec.Cmd, error)
+	workers []*powerShellWorker
+	idle    []*powerShellWorker
+}
+
+// sharedPowerShell is the pool used by runPowerShellScript
+var sharedPowerShell = newPowerShellPool(1, powerShellWorkerCommand)
+
+// runPowerShellScript runs an embedded script from pwsh/ in a shared worker and returns its output
+func runPowerShellScript(name string, params scriptParams) ([]byte, error) {
+	return sharedPowerShell.run(name, params)
+}
+
+// runPowerShellScriptIsolated runs a script in a fresh worker that is stopped afterwards, for
+// scripts such as test runs that may leave state behind in the process. A crash is not retried.
+func runPowerShellScriptIsolated(name string, params scriptParams, timeout time.Duration) ([]byte, error) {
+	return runIsolatedScript(powerShellWorkerCommand, name, params, timeout)
+}
+
+// runIsolatedScript runs a script in a fresh worker started by command
+func runIsolatedScript(command func() (*exec.Cmd, error), name string, params scriptParams, timeout time.Duration) ([]byte, error) {
+	script, err := materializeScript(name)
+	if err != nil {
+		return nil, err
+	}
+	worker := newPowerShellWorker(command)
+	worker.timeout = timeout
+	defer worker.stop()
+
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+	output, err := worker.request(workerRequest{Script: script, Parameters: params})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return output, nil
+}
+
+// setPowerShellWorkers sets how many shared workers may run scripts at the same time
+func setPowerShellWorkers(n int) {
+	sharedPowerShell.resize(n)
+}
+
+// stopPowerShellWorkers shuts down every shared worker that was started
+func stopPowerShellWorkers() {
+	sharedPowerShell.stop()
+}
+
+func newPowerShellPool(size int, command func() (*exec.Cmd, error)) *powerShellPool {
+	pool := &powerShellPool{size: size, command: command}
+	pool.cond = sync.NewCond(&pool.mu)
+	return pool
+}
+
+// run runs a script on an idle worker, waiting for one when all are busy
+func (p *powerShellPool) run(name string, params scriptParams) ([]byte, error) {
+	worker := p.acquire()
+	defer p.release(worker)
+	return worker.run(name, params)
+}
+
+func (p *powerShellPool) acquire() *powerShellWorker {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		if n := len(p.idle); n > 0 {
+			worker := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			return worker
+		}
+		if len(p.workers) < p.size {
+			worker := newPowerShellWorker(p.command)
+			p.workers = append(p.workers, worker)
+			return worker
+		}
+		p.cond.Wait()
+	}
+}
+
+func (p *powerShellPool) release(worker *powerShellWorker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.idle = append(p.idle, worker)
+	p.cond.Signal()
+}
+
+// resize changes the number of workers the pool may start; at least one is always allowed
+func (p *powerShellPool) resize(n int) {
+	if n < 1 {
+		n = 1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.size = n
+	p.cond.Broadcast()
+}
+
+// stop shuts down all workers in parallel. The pool can still be used afterwards.
+func (p *powerShellPool) stop() {
+	p.mu.Lock()
+	workers := p.workers
+	p.workers = nil
+	p.idle = nil
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, worker := range workers {
+		wg.Add(1)
+		go func(worker *powerShellWorker) {
+			defer wg.Done()
+			worker.stop()
+		}(worker)
+	}
+	wg.Wait()
+}
+
+// powerShellWorkerCommand builds the command that starts pwsh/worker.ps1
+func powerShellWorkerCommand() (*exec.Cmd, error) {
+	executable, err := powerShellExecutable()
+	if err != nil {
+		return nil, err
+	}
+	return powerShellWorkerCommandFor(executable)()
+}
+
+// powerShellWorkerCommandFor returns a function building the command that starts
+// pwsh/worker.ps1 with a given PowerShell executable
+func powerShellWorkerCommandFor(executable string) func() (*exec.Cmd, error) {
+	return func() (*exec.Cmd, error) {
+		script, err := materializeScript("worker.ps1")
+		if err != nil {
+			return nil, err
+		}
+		return powerShellScriptCommand(executable, script), nil
+	}
+}
+
+func newPowerShellWorker(command func() (*exec.Cmd, error)) *powerShellWorker {
+	return &powerShellWorker{command: command, timeout: powerShellTimeout}
+}
+
+// run sends a request for the named script. If the worker has crashed, it is restarted and
+// the request retried once; a request that times out kills the worker and is not retried.
+func (w *powerShellWorker) run(name string, params scriptParams) ([]byte, error) {
+	script, err := materializeScript(name)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	output, err := w.request(workerRequest{Script: script, Parameters: params})
+	if errors.Is(err, errWorkerExited) {
+		output, err = w.request(workerRequest{Script: script, Parameters: params})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return output, nil
+}
+
+// request sends one request to the running worker, starting it first if needed
+func (w *powerShellWorker) request(req workerRequest) ([]byte, error) {
+	if w.process == nil {
+		process, err := w.start()
+		if err != nil {
+			return nil, err
+		}
+		w.process = process
+	}
+	process := w.process
+
+	w.nextID++
+	req.ID = w.nextID
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := process.stdin.Write(append(line, '\n')); err != nil {
+		w.discard()
+		return nil, w.exitError(process)
+	}
+
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case response, ok := <-process.responses:
+			if !ok {
+				w.discard()
+				return nil, w.exitError(process)
+			}
+			if response.ID != req.ID {
+				continue
+			}
+			if response.Error != "" {
+				return nil, errors.New(response.Error)
+			}
+			return []byte(response.Output), nil
+		case <-timer.C:
+			w.discard()
+			return nil, fmt.Errorf("PowerShell did not respond within %s", w.timeout)
+		}
+	}
+}
+
+// start launches a worker process and waits until it reports that it is ready
+func (w *powerShellWorker) start() (*workerProcess, error) {
+	cmd, err := w.command()
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	process := &workerProcess{
+		cmd:       cmd,
+		stdin:     stdin,
+		responses: make(chan workerResponse),
+		exited:    make(chan struct{}),
+		stderr:    &lockedBuffer{},
+	}
+	cmd.Stderr = process.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		readWorkerResponses(stdout, process.responses)
+		_ = cmd.Wait()
+		close(process.exited)
+	}()
+
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case response, ok := <-process.responses:
+			if !ok {
+				process.kill()
+				return nil, fmt.Errorf("PowerShell worker failed to start: %s", process.stderr.String())
+			}
+			if response.Ready {
+				return process, nil
+			}
+		case <-timer.C:
+			process.kill()
+			return nil, fmt.Errorf("PowerShell worker did not start within %s", w.timeout)
+		}
+	}
+}
+
+// readWorkerResponses decodes response lines until stdout closes. Lines that are not
+// responses, such as output PowerShell writes directly to the console, are skipped.
+func readWorkerResponses(stdout io.Reader, responses chan<- workerResponse) {
+	defer close(responses)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(bytes.TrimPrefix(scanner.Bytes(), []byte("\xef\xbb\xbf")))
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var response workerResponse
+		if json.Unmarshal(line, &response) == nil {
+			responses <- response
+		}
+	}
+	// Keep reading after an oversized line so the process never blocks on a full stdout
+	_, _ = io.Copy(io.Discard, stdout)
+}
+
+// exitError describes a worker that went away, including what it wrote to stderr
+func (w *powerShellWorker) exitError(process *workerProcess) error {
+	select {
+	case <-process.exited:
+	case <-time.After(time.Second):
+	}
+	if stderr := process.stderr.String(); stderr != "" {
+		return fmt.Errorf("%w: %s", errWorkerExited, stderr)
+	}
+	return errWorkerExited
+}
+
+// discard kills the current worker so the next request starts a fresh one
+func (w *powerShellWorker) discard() {
+	if w.process != nil {
+		w.process.kill()
+		w.process = nil
+	}
+}
+
+// stop asks the worker to exit, killing it if it does not do so promptly
+func (w *powerShellWorker) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	process := w.process
+	w.process = nil
+	if process == nil {
+		return
+	}
+
+	if line, err := json.Marshal(workerRequest{Op: "exit"}); err == nil {
+		_, _ = process.stdin.Write(append(line, '\n'))
+	}
+	_ = process.stdin.Close()
+
+	go process.drain()
+	select {
+	case <-process.exited:
+	case <-time.After(2 * time.Second):
+		process.kill()
+	}
+}
+
+// kill ends the process; its exit is still collected in the background
+func (p *workerProcess) kill() {
+	_ = p.stdin.Close()
+	if p.cmd.Process != nil {
+		_ = p.cmd.Process.Kill()
+	}
+	go p.drain()
+}
+
+// drain discards responses nobody is waiting for, so the reader can finish
+func (p *workerProcess) drain() {
+	for range p.responses {
+	}
+}
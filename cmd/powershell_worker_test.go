@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWorkerHelperProcess stands in for pwsh/worker.ps1 when started by fakeWorkerCommand. It echoes
+// each request's parameters back, crashing or hanging when the parameters ask it to.
+func TestWorkerHelperProcess(t *testing.T) {
+	if os.Getenv("PWSH_SKILLS_FAKE_WORKER") != "1" {
+		return
+	}
+	fmt.Println(`{"id":0,"ready":true}`)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req workerRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.Op == "exit" {
+			break
+		}
+		if marker, ok := req.Parameters["crashOnce"].(string); ok && os.Remove(marker) == nil {
+			fmt.Fprintln(os.Stderr, "boom")
+			os.Exit(1)
+		}
+		if delay, ok := req.Parameters["delay"].(float64); ok {
+			time.Sleep(time.Duration(delay) * time.Millisecond)
+		}
+		if req.Parameters["hang"] == true {
+			time.Sleep(time.Minute)
+		}
+		output, _ := json.Marshal(req.Parameters)
+		response, _ := json.Marshal(workerResponse{ID: req.ID, Output: string(output)})
+		fmt.Println("stray console output")
+		fmt.Println(string(response))
+	}
+	os.Exit(0)
+}
+
+// fakeWorkerCommand starts TestWorkerHelperProcess as a worker process
+func fakeWorkerCommand() (*exec.Cmd, error) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestWorkerHelperProcess$")
+	cmd.Env = append(os.Environ(), "PWSH_SKILLS_FAKE_WORKER=1")
+	return cmd, nil
+}
+
+// fakeWorker returns a worker backed by TestWorkerHelperProcess and a counter of processes started
+func fakeWorker(t *testing.T) (*powerShellWorker, *int) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	started := 0
+	worker := newPowerShellWorker(func() (*exec.Cmd, error) {
+		started++
+		return fakeWorkerCommand()
+	})
+	t.Cleanup(worker.stop)
+	return worker, &started
+}
+
+func TestPowerShellWorkerReusesProcess(t *testing.T) {
+	worker, started := fakeWorker(t)
+
+	for i := 0; i < 3; i++ {
+		output, err := worker.run("version.ps1", scriptParams{"Name": fmt.Sprintf(`it's $(%d)`, i)})
+		if err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+		if want := fmt.Sprintf(`{"Name":"it's $(%d)"}`, i); string(output) != want {
+			t.Errorf("Expected %s, got %s", want, output)
+		}
+	}
+	if *started != 1 {
+		t.Errorf("Expected one worker process, started %d", *started)
+	}
+}
+
+func TestPowerShellWorkerRecoversFromCrash(t *testing.T) {
+	worker, started := fakeWorker(t)
+	marker := filepath.Join(t.TempDir(), "crash")
+	if err := os.WriteFile(marker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := worker.run("version.ps1", nil); err != nil {
+		t.Fatalf("First request failed: %v", err)
+	}
+	if _, err := worker.run("version.ps1", scriptParams{"crashOnce": marker}); err != nil {
+		t.Fatalf("Expected the request to be retried after a crash, got %v", err)
+	}
+	if *started != 2 {
+		t.Errorf("Expected the worker to be restarted once, started %d", *started)
+	}
+}
+
+func TestPowerShellWorkerTimeout(t *testing.T) {
+	worker, started := fakeWorker(t)
+	worker.timeout = 500 * time.Millisecond
+
+	_, err := worker.run("version.ps1", scriptParams{"hang": true})
+	if err == nil || !strings.Contains(err.Error(), "did not respond") {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+	if _, err := worker.run("version.ps1", nil); err != nil {
+		t.Fatalf("Expected a fresh worker after the timeout, got %v", err)
+	}
+	if *started != 2 {
+		t.Errorf("Expected the worker to be restarted after the timeout, started %d", *started)
+	}
+}
+
+func TestPowerShellWorkerStop(t *testing.T) {
+	worker, _ := fakeWorker(t)
+	if _, err := worker.run("version.ps1", nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	process := worker.process
+
+	worker.stop()
+	select {
+	case <-process.exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Worker did not exit after stop")
+	}
+	if worker.process != nil {
+		t.Error("Expected no running worker after stop")
+	}
+}
+
+func TestPowerShellPoolRunsConcurrently(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	var started int32
+	pool := newPowerShellPool(3, func() (*exec.Cmd, error) {
+		atomic.AddInt32(&started, 1)
+		return fakeWorkerCommand()
+	})
+	t.Cleanup(pool.stop)
+
+	outputs := make([]string, 12)
+	errs := make([]error, len(outputs))
+	var wg sync.WaitGroup
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			output, err := pool.run("version.ps1", scriptParams{"delay": 50, "index": i})
+			outputs[i], errs[i] = string(output), err
+		}(i)
+	}
+	wg.Wait()
+
+	for i, output := range outputs {
+		if errs[i] != nil {
+			t.Fatalf("Request %d failed: %v", i, errs[i])
+		}
+		if want := fmt.Sprintf(`{"delay":50,"index":%d}`, i); output != want {
+			t.Errorf("Expected %s, got %s", want, output)
+		}
+	}
+	if n := atomic.LoadInt32(&started); n < 1 || n > 3 {
+		t.Errorf("Expected between 1 and 3 workers, started %d", n)
+	}
+}
@@ -0,0 +1,12 @@
+# Looks up a conceptual about_ topic with Get-Help and writes its raw text as JSON.
+param(
+    [Parameter(Mandatory)]
+    [string]$Name
+)
+
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+
+$help = Get-Help -Name $Name -ErrorAction SilentlyContinue | Select-Object -First 1
+if (-not $help -or ($help -isnot [string] -and $help.Category -ne 'HelpFile')) { @{ found = $false } | ConvertTo-Json -Compress; exit 0 }
+$body = if ($help -is [string]) { $help } else { ($help | Out-String -Width 120) }
+@{ found = $true; name = $(if ($help -is [string]) { $Name } else { $help.Name }); text = $body } | ConvertTo-Json -Compress
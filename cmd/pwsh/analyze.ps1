@@ -0,0 +1,43 @@
+# Runs PSScriptAnalyzer on one file and writes its diagnostics as JSON, or {"available":false}
+# when the PSScriptAnalyzer module is not installed. Settings is a settings file path or the
+# name of a built-in preset such as PSGallery.
+param(
+    [Parameter(Mandatory)]
+    [string]$Path,
+    [string]$Settings
+)
+
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+
+# Checking the loaded modules first keeps repeated calls in the worker fast
+if (-not (Get-Module -Name PSScriptAnalyzer) -and -not (Get-Module -ListAvailable -Name PSScriptAnalyzer)) {
+    @{ available = $false } | ConvertTo-Json -Compress
+    exit 0
+}
+Import-Module PSScriptAnalyzer -ErrorAction Stop
+
+# Invoke-ScriptAnalyzer has no -LiteralPath, so paths with wildcard characters are analysed
+# from their content instead
+$arguments = @{}
+if ([System.Management.Automation.WildcardPattern]::ContainsWildcardCharacters($Path)) {
+    $arguments.ScriptDefinition = [System.IO.File]::ReadAllText($Path)
+} else {
+    $arguments.Path = $Path
+}
+if ($Settings) { $arguments.Settings = $Settings }
+
+$diagnostics = @(Invoke-ScriptAnalyzer @arguments | ForEach-Object {
+    [ordered]@{
+        ruleName = $_.RuleName
+        severity = "$($_.Severity)"
+        line     = [int]$_.Line
+        column   = [int]$_.Column
+        message  = $_.Message
+    }
+})
+
+ConvertTo-Json -Depth 4 -Compress -InputObject ([ordered]@{
+    available   = $true
+    version     = "$((Get-Module -Name PSScriptAnalyzer | Select-Object -First 1).Version)"
+    diagnostics = $diagnostics
+})
@@ -0,0 +1,5 @@
+# Writes the version of the newest installed PSScriptAnalyzer module, or nothing when it is not installed.
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+
+$module = Get-Module -ListAvailable -Name PSScriptAnalyzer | Sort-Object -Property Version -Descending | Select-Object -First 1
+if ($module) { "$($module.Version)" }
@@ -0,0 +1,57 @@
+# Looks up a command with Get-Command and Get-Help and writes its help as JSON.
+# Parameters come from Get-Command when help content is not installed.
+param(
+    [Parameter(Mandatory)]
+    [string]$Name
+)
+
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+
+$command = Get-Command -Name $Name -ErrorAction SilentlyContinue | Select-Object -First 1
+if ($command -and $command.CommandType -eq 'Alias') { $command = $command.ResolvedCommand }
+if (-not $command) { @{ found = $false } | ConvertTo-Json -Compress; exit 0 }
+
+$help = Get-Help -Name $command.Name -Full -ErrorAction SilentlyContinue | Select-Object -First 1
+$text = { param($value) (@($value) | ForEach-Object { $_.Text }) -join [Environment]::NewLine }
+$common = @([System.Management.Automation.Cmdlet]::CommonParameters) + @([System.Management.Automation.Cmdlet]::OptionalCommonParameters)
+
+$parameters = @()
+if ($help -and $help.parameters -and $help.parameters.parameter -and (& $text $help.parameters.parameter[0].description)) {
+    $parameters = @($help.parameters.parameter | ForEach-Object {
+        [ordered]@{
+            name = $_.name; type = "$($_.type.name)"; required = $_.required -eq 'true'
+            position = "$($_.position)"; pipelineInput = "$($_.pipelineInput)"; description = (& $text $_.description).Trim()
+        }
+    })
+} else {
+    $parameters = @($command.Parameters.Values | Where-Object { $common -notcontains $_.Name } | ForEach-Object {
+        $attribute = $_.Attributes | Where-Object { $_ -is [System.Management.Automation.ParameterAttribute] } | Select-Object -First 1
+        [ordered]@{
+            name = $_.Name; type = $_.ParameterType.Name; required = [bool]($attribute -and $attribute.Mandatory)
+            position = $(if ($attribute -and $attribute.Position -ge 0) { "$($attribute.Position)" } else { 'named' })
+            pipelineInput = $(if ($attribute -and $attribute.ValueFromPipeline) { 'true' } else { 'false' }); description = ''
+        }
+    })
+}
+
+$examples = @()
+if ($help -and $help.examples) {
+    $examples = @($help.examples.example | ForEach-Object {
+        [ordered]@{ title = ("$($_.title)" -replace '^-+\s*|\s*-+$', ''); code = "$($_.code)"; remarks = (& $text $_.remarks).Trim() }
+    })
+}
+
+$synopsis = if ($help -and $help.Synopsis -and -not $help.Synopsis.Trim().StartsWith($command.Name)) { $help.Synopsis.Trim() } else { '' }
+
+[ordered]@{
+    found = $true
+    name = $command.Name
+    commandType = "$($command.CommandType)"
+    module = "$($command.ModuleName)"
+    synopsis = $synopsis
+    description = $(if ($help) { (& $text $help.description).Trim() } else { '' })
+    syntax = @($command.ParameterSets | ForEach-Object { "$($command.Name) $_".Trim() })
+    parameters = $parameters
+    examples = $examples
+    helpUri = "$($command.HelpUri)"
+} | ConvertTo-Json -Depth 5 -Compress
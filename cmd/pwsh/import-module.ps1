@@ -0,0 +1,26 @@
+# Imports a module from its manifest or .psm1 file and writes the outcome as JSON: the errors
+# and warnings raised while importing and the functions it exports. Runs in a fresh PowerShell
+# process, so nothing the module does at import time leaks into other checks.
+param(
+    [Parameter(Mandatory)]
+    [string]$Path
+)
+
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+
+$importErrors = @()
+$importWarnings = @()
+$exported = @()
+try {
+    $module = Import-Module -Name $Path -Force -PassThru -ErrorAction Stop -ErrorVariable +importErrors -WarningVariable importWarnings 3>$null
+    $exported = @($module | ForEach-Object { $_.ExportedFunctions.Keys } | Sort-Object -Unique)
+} catch {
+    $importErrors += $_
+}
+
+ConvertTo-Json -Depth 3 -Compress -InputObject ([ordered]@{
+    imported  = $importErrors.Count -eq 0
+    errors    = @($importErrors | ForEach-Object { $_.Exception.Message } | Select-Object -Unique)
+    warnings  = @($importWarnings | ForEach-Object { "$_" })
+    functions = $exported
+})
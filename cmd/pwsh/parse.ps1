@@ -0,0 +1,226 @@
+# Parses each file with the PowerShell language parser and writes its parse errors and syntax
+# tree as JSON: the token stream, the commands it invokes with their parameters, the functions
+# it defines, its [SuppressMessage()] attributes, the syntax that needs a newer PowerShell, its
+# #Requires -Version and, for a .psd1 data file, the keys of its hashtable, each with its exact
+# extent. Paths are passed as arguments and never interpolated into script text.
+param(
+    [Parameter(ValueFromRemainingArguments)]
+    [string[]]$Path
+)
+
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+
+$skippedTokens = @('NewLine', 'LineContinuation', 'EndOfInput')
+
+function ConvertTo-Extent($extent) {
+    [ordered]@{
+        line      = $extent.StartLineNumber
+        column    = $extent.StartColumnNumber
+        endLine   = $extent.EndLineNumber
+        endColumn = $extent.EndColumnNumber
+    }
+}
+
+# Flattens the keys of a data file's hashtable, naming nested keys like PrivateData.PSData.Tags.
+# Values are read with SafeGetValue, which never runs code; other values are not constant.
+function ConvertTo-ManifestEntry($hashtable, $prefix) {
+    foreach ($pair in $hashtable.KeyValuePairs) {
+        $key = $pair.Item1.Extent.Text
+        if ($pair.Item1 -is [System.Management.Automation.Language.StringConstantExpressionAst]) {
+            $key = $pair.Item1.Value
+        }
+        $name = "$prefix$key"
+        $value = $pair.Item2
+        if ($value -is [System.Management.Automation.Language.PipelineAst] -and $value.PipelineElements.Count -eq 1) {
+            $value = $value.PipelineElements[0].Expression
+        }
+        if ($value -is [System.Management.Automation.Language.HashtableAst]) {
+            ConvertTo-ManifestEntry $value "$name."
+            continue
+        }
+        $entry = ConvertTo-Extent $pair.Item1.Extent
+        $entry.key = $name
+        $entry.values = @()
+        $entry.constant = $false
+        try {
+            $entry.values = @(@($value.SafeGetValue()) | Where-Object { $null -ne $_ } | ForEach-Object { "$_" })
+            $entry.constant = $true
+        } catch { }
+        $entry
+    }
+}
+
+$results = foreach ($file in $Path) {
+    $tokens = $null
+    $errors = $null
+    $tree = [ordered]@{ tokens = @(); commands = @(); functions = @(); suppressions = @(); attributes = @(); dotSources = @(); features = @(); requiredVersion = ''; manifest = $null }
+    try {
+        $ast = [System.Management.Automation.Language.Parser]::ParseFile($file, [ref]$tokens, [ref]$errors)
+        $diagnostics = @($errors | ForEach-Object {
+            [ordered]@{
+                line      = $_.Extent.StartLineNumber
+                column    = $_.Extent.StartColumnNumber
+                endLine   = $_.Extent.EndLineNumber
+                endColumn = $_.Extent.EndColumnNumber
+                text      = $_.Extent.Text
+                errorId   = $_.ErrorId
+                message   = $_.Message
+            }
+        })
+
+        $tokenAt = @{}
+        for ($i = 0; $i -lt $tokens.Count; $i++) {
+            $tokenAt[$tokens[$i].Extent.StartOffset] = $i
+        }
+
+        $tree.tokens = @($tokens | Where-Object { $skippedTokens -notcontains "$($_.Kind)" } | ForEach-Object {
+            $token = ConvertTo-Extent $_.Extent
+            $token.kind = "$($_.Kind)"
+            $token.text = $_.Text
+            $token.value = if ($_ -is [System.Management.Automation.Language.StringToken]) { $_.Value } else { '' }
+            $token
+        })
+
+        $tree.commands = @($ast.FindAll({ param($node) $node -is [System.Management.Automation.Language.CommandAst] }, $true) | ForEach-Object {
+            $name = $_.GetCommandName()
+            if ($name) {
+                $command = ConvertTo-Extent $_.CommandElements[0].Extent
+                $command.name = $name
+                $command.parameters = @($_.CommandElements | Where-Object {
+                    $_ -is [System.Management.Automation.Language.CommandParameterAst]
+                } | ForEach-Object { $_.ParameterName })
+                $command
+            }
+        })
+
+        $tree.functions = @($ast.FindAll({ param($node) $node -is [System.Management.Automation.Language.FunctionDefinitionAst] }, $true) | ForEach-Object {
+            # Point at the function's name, the token after the function keyword
+            $extent = $_.Extent
+            $keyword = $tokenAt[$extent.StartOffset]
+            if ($null -ne $keyword -and $keyword + 1 -lt $tokens.Count) {
+                $extent = $tokens[$keyword + 1].Extent
+            }
+            $paramBlock = $_.Body.ParamBlock
+            $function = ConvertTo-Extent $extent
+            $function.name = $_.Name
+            $function.hasParamBlock = $null -ne $paramBlock
+            $function.hasParameters = $null -ne $_.Parameters
+            $function.isFilter = $_.IsFilter
+            $function.body = ConvertTo-Extent $_.Body.Extent
+            $function.paramBlock = if ($paramBlock) { ConvertTo-Extent $paramBlock.Extent }
+            $parent = $_.Parent
+            while ($parent -and $parent -isnot [System.Management.Automation.Language.FunctionDefinitionAst]) {
+                $parent = $parent.Parent
+            }
+            $function.nested = $null -ne $parent
+            # A function reading $args takes arguments no param() block declares
+            $function.usesArgs = $null -ne $_.Body.Find({ param($node)
+                $node -is [System.Management.Automation.Language.VariableExpressionAst] -and $node.VariablePath.UserPath -eq 'args'
+            }, $true)
+            $function.hasCmdletBinding = $null -ne $paramBlock -and [bool]($paramBlock.Attributes | Where-Object {
+                $_.TypeName.Name -in 'CmdletBinding', 'CmdletBindingAttribute'
+            })
+            $function
+        })
+
+        # Dot-sourced scripts, with the path relative to the script's directory when it is
+        # written out, as in . $PSScriptRoot/Public/Get-Thing.ps1, and '' when it is only
+        # known at run time
+        $tree.dotSources = @($ast.FindAll({ param($node)
+            $node -is [System.Management.Automation.Language.CommandAst] -and
+            $node.InvocationOperator -eq [System.Management.Automation.Language.TokenKind]::Dot
+        }, $true) | ForEach-Object {
+            $target = $_.CommandElements[0]
+            $dotSource = ConvertTo-Extent $target.Extent
+            $dotSource.path = ''
+            if ($target -is [System.Management.Automation.Language.StringConstantExpressionAst]) {
+                $dotSource.path = $target.Value
+            } elseif ($target -is [System.Management.Automation.Language.ExpandableStringExpressionAst]) {
+                $path = $target.Value -replace '^\$(PSScriptRoot|\{PSScriptRoot\})[\\/]?', ''
+                if ($path -notmatch '\$') {
+                    $dotSource.path = $path
+                }
+            }
+            $dotSource
+        })
+
+        $tree.attributes = @($ast.FindAll({ param($node) $node -is [System.Management.Automation.Language.AttributeAst] }, $true) | ForEach-Object {
+            ConvertTo-Extent $_.Extent
+        })
+
+        # A [SuppressMessage('RuleId', '')] on a param() block covers the script block it
+        # belongs to: the whole file for the script's own param() block. In a function it
+        # covers the whole definition, since rules such as BP001 report the function name.
+        $tree.suppressions = @($ast.FindAll({ param($node)
+            $node -is [System.Management.Automation.Language.AttributeAst] -and
+            $node.Parent -is [System.Management.Automation.Language.ParamBlockAst] -and
+            $node.TypeName.FullName -match '^(System\.)?(Diagnostics\.CodeAnalysis\.)?SuppressMessage(Attribute)?$'
+        }, $true) | ForEach-Object {
+            $rule = $_.PositionalArguments | Select-Object -First 1
+            if ($rule -is [System.Management.Automation.Language.StringConstantExpressionAst]) {
+                $suppression = ConvertTo-Extent $_.Extent
+                $suppression.rule = $rule.Value
+                $scope = $_.Parent.Parent
+                if ($scope.Parent -is [System.Management.Automation.Language.FunctionDefinitionAst]) {
+                    $scope = $scope.Parent
+                }
+                $suppression.scope = ConvertTo-Extent $scope.Extent
+                $suppression
+            }
+        })
+
+        # Syntax added after Windows PowerShell 5.1. Node types are compared by name, since
+        # Windows PowerShell does not know the newer ones.
+        $tree.features = @($ast.FindAll({ $true }, $true) | ForEach-Object {
+            $node = $_
+            $kind = switch ($node.GetType().Name) {
+                'TernaryExpressionAst' { 'Ternary' }
+                'PipelineChainAst' { if ("$($node.Operator)" -eq 'AndAnd') { 'PipelineChainAnd' } else { 'PipelineChainOr' } }
+                'BinaryExpressionAst' { if ("$($node.Operator)" -eq 'QuestionQuestion') { 'NullCoalescing' } }
+                'AssignmentStatementAst' { if ("$($node.Operator)" -eq 'QuestionQuestionEquals') { 'NullCoalescingAssignment' } }
+                'IndexExpressionAst' { if ($node.NullConditional) { 'NullConditionalIndex' } }
+                default {
+                    if ($node -is [System.Management.Automation.Language.MemberExpressionAst] -and $node.NullConditional) {
+                        'NullConditionalMember'
+                    }
+                }
+            }
+            if ($kind) {
+                $feature = ConvertTo-Extent $node.Extent
+                $feature.kind = $kind
+                $feature
+            }
+        })
+        if ($ast.ScriptRequirements -and $ast.ScriptRequirements.RequiredPSVersion) {
+            $tree.requiredVersion = "$($ast.ScriptRequirements.RequiredPSVersion)"
+        }
+
+        # A module manifest or other data file is a single hashtable
+        if ([System.IO.Path]::GetExtension($file) -eq '.psd1' -and $errors.Count -eq 0) {
+            $hashtable = $ast.Find({ param($node) $node -is [System.Management.Automation.Language.HashtableAst] }, $false)
+            if ($hashtable) {
+                $tree.manifest = @(ConvertTo-ManifestEntry $hashtable '')
+            }
+        }
+    } catch {
+        $diagnostics = @([ordered]@{
+            line = 0; column = 0; endLine = 0; endColumn = 0; text = ''
+            errorId = 'FileNotReadable'; message = $_.Exception.Message
+        })
+    }
+    [ordered]@{
+        path            = $file
+        diagnostics     = $diagnostics
+        tokens          = $tree.tokens
+        commands        = $tree.commands
+        functions       = $tree.functions
+        suppressions    = $tree.suppressions
+        attributes      = $tree.attributes
+        dotSources      = $tree.dotSources
+        features        = $tree.features
+        requiredVersion = $tree.requiredVersion
+        manifest        = $tree.manifest
+    }
+}
+
+ConvertTo-Json -InputObject @($results) -Depth 6 -Compress
@@ -0,0 +1,76 @@
+# Runs Pester on the given test files and writes every test's outcome as JSON, or
+# {"available":false} when Pester is not installed. Pester 5 and the older Pester 3/4
+# result objects are both converted to the same shape.
+param(
+    [Parameter(Mandatory)]
+    [string[]]$Path
+)
+
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+
+$module = Get-Module -ListAvailable -Name Pester | Sort-Object -Property Version -Descending | Select-Object -First 1
+if (-not $module) {
+    @{ available = $false } | ConvertTo-Json -Compress
+    exit 0
+}
+Import-Module $module -ErrorAction Stop
+
+$tests = @()
+if ($module.Version.Major -ge 5) {
+    $configuration = New-PesterConfiguration
+    $configuration.Run.Path = $Path
+    $configuration.Run.PassThru = $true
+    $configuration.Output.Verbosity = 'None'
+    $result = Invoke-Pester -Configuration $configuration
+
+    $tests = @($result.Tests | ForEach-Object {
+        $message = ''
+        if ($_.ErrorRecord) {
+            $message = (@($_.ErrorRecord) | ForEach-Object { $_.Exception.Message }) -join [Environment]::NewLine
+        }
+        [ordered]@{
+            name       = $_.ExpandedName
+            path       = ($_.Path | Select-Object -SkipLast 1) -join ' '
+            file       = "$($_.ScriptBlock.File)"
+            line       = [int]$_.StartLine
+            result     = "$($_.Result)"
+            durationMs = [math]::Round($_.Duration.TotalMilliseconds, 1)
+            message    = $message
+        }
+    })
+
+    # Failures outside of tests, such as a syntax error in a test file, have no test of their own
+    foreach ($container in @($result.Containers | Where-Object { $_.Result -eq 'Failed' -and $_.ErrorRecord })) {
+        $tests += [ordered]@{
+            name       = ''
+            path       = ''
+            file       = "$($container.Item)"
+            line       = 0
+            result     = 'Failed'
+            durationMs = [math]::Round($container.Duration.TotalMilliseconds, 1)
+            message    = (@($container.ErrorRecord) | ForEach-Object { $_.Exception.Message }) -join [Environment]::NewLine
+        }
+    }
+} else {
+    $result = Invoke-Pester -Script $Path -PassThru -Show None
+
+    $tests = @($result.TestResult | ForEach-Object {
+        $line = 0
+        if ($_.StackTrace -match ':\s*(\d+)') { $line = [int]$Matches[1] }
+        [ordered]@{
+            name       = $_.Name
+            path       = (@($_.Describe, $_.Context) | Where-Object { $_ }) -join ' '
+            file       = ''
+            line       = $line
+            result     = "$($_.Result)"
+            durationMs = [math]::Round($_.Time.TotalMilliseconds, 1)
+            message    = "$($_.FailureMessage)"
+        }
+    })
+}
+
+ConvertTo-Json -Depth 4 -Compress -InputObject ([ordered]@{
+    available = $true
+    version   = "$($module.Version)"
+    tests     = $tests
+})
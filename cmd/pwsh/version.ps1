@@ -0,0 +1,4 @@
+# Writes the version of the running PowerShell.
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+
+$PSVersionTable.PSVersion.ToString()
@@ -0,0 +1,49 @@
+# Serves helper scripts to the CLI over stdin/stdout as JSON lines, so one PowerShell process
+# handles every check instead of starting PowerShell per file. A request names a script next
+# to this one and its parameters, which are splatted as a hashtable and never formatted into
+# script text:
+#   {"id":1,"script":"/cache/scripts/parse-0a1b2c.ps1","parameters":{"Path":["/src/a.ps1"]}}
+# Each request gets exactly one response line:
+#   {"id":1,"output":"[...]","error":""}
+# The worker exits on {"op":"exit"} or when stdin is closed.
+
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+$ProgressPreference = 'SilentlyContinue'
+
+function Send-Response($response) {
+    [Console]::Out.WriteLine(($response | ConvertTo-Json -Compress -Depth 2))
+    [Console]::Out.Flush()
+}
+
+Send-Response ([ordered]@{ id = 0; ready = $true })
+
+while ($null -ne ($line = [Console]::In.ReadLine())) {
+    if (-not $line.Trim()) { continue }
+
+    $response = [ordered]@{ id = 0; output = ''; error = '' }
+    try {
+        $request = $line | ConvertFrom-Json
+        $response.id = [int]$request.id
+        if ($request.op -eq 'exit') { break }
+
+        $script = [string]$request.script
+        if (-not $script -or (Split-Path -Parent $script) -ne $PSScriptRoot -or -not (Test-Path -LiteralPath $script -PathType Leaf)) {
+            throw "Unknown helper script: $script"
+        }
+
+        $parameters = @{}
+        if ($request.parameters) {
+            foreach ($property in $request.parameters.PSObject.Properties) {
+                $parameters[$property.Name] = $property.Value
+            }
+        }
+
+        # Only the success stream is the script's output; Write-Host and friends must not
+        # reach stdout, where they would corrupt the protocol
+        $output = & $script @parameters 2>$null 3>$null 4>$null 5>$null 6>$null
+        $response.output = (@($output) | ForEach-Object { "$_" }) -join "`n"
+    } catch {
+        $response.error = $_.Exception.Message
+    }
+    Send-Response $response
+}
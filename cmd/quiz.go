@@ -0,0 +1,463 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	quizCourse    string
+	quizQuestions int
+	quizWeakOnly  bool
+)
+
+var quizCmd = &cobra.Command{
+	Use:   "quiz",
+	Short: "Test your knowledge with a short interactive quiz",
+	Long: `Run a short multiple-choice and fill-in-the-blank quiz generated from the course hints.
+
+The quiz uses the current course when run from a course directory, or every course
+otherwise. Results are recorded per course so weak topics can be revisited with --weak.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return startQuiz()
+	},
+}
+
+// Question kinds
+const (
+	questionTopic  = "topic"  // pick the topic that matches a description
+	questionCmdlet = "cmdlet" // pick the cmdlet missing from a description
+	questionBlank  = "blank"  // type the cmdlet missing from an example
+)
+
+// weakTopicThreshold is the accuracy below which a topic is suggested for revision
+const weakTopicThreshold = 0.6
+
+// QuizQuestion is a single generated question
+type QuizQuestion struct {
+	HintID   string
+	Category string
+	Kind     string
+	Prompt   string
+	Context  string
+	Options  []string
+	Answer   string
+}
+
+// QuizResults are the recorded quiz results for every course
+type QuizResults struct {
+	Courses map[string]*CourseQuizResults `json:"courses"`
+}
+
+// CourseQuizResults are the recorded quiz results for one course
+type CourseQuizResults struct {
+	Attempts  int                    `json:"attempts"`
+	LastScore int                    `json:"last_score"`
+	LastTotal int                    `json:"last_total"`
+	LastTaken time.Time              `json:"last_taken"`
+	Topics    map[string]*TopicScore `json:"topics"`
+}
+
+// TopicScore counts the answers given for one hint topic
+type TopicScore struct {
+	Asked   int `json:"asked"`
+	Correct int `json:"correct"`
+}
+
+// Accuracy returns the share of correct answers for the topic
+func (s TopicScore) Accuracy() float64 {
+	if s.Asked == 0 {
+		return 1
+	}
+	return float64(s.Correct) / float64(s.Asked)
+}
+
+var cmdletNamePattern = regexp.MustCompile(`\b[A-Z][a-z]+-[A-Z][A-Za-z]+\b`)
+
+func startQuiz() error {
+	fmt.Println(T("quiz.title"))
+	fmt.Println("=============================================")
+
+	categories, err := quizCategories(quizCourse)
+	if err != nil {
+		fmt.Println(T("quiz.unknown_course", quizCourse, strings.Join(hintCategories, ", ")))
+		return reported(exitError(exitUsage, err))
+	}
+
+	results, err := loadQuizResults()
+	if err != nil {
+		fmt.Println(T("quiz.results_load_error", err))
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var questions []QuizQuestion
+	if quizWeakOnly {
+		questions = weakTopicQuestions(categories, results, quizQuestions, r)
+		if len(questions) == 0 {
+			fmt.Println(T("quiz.no_weak_topics"))
+			return nil
+		}
+	} else {
+		questions = generateQuizQuestions(categories, quizQuestions, r)
+	}
+	if len(questions) == 0 {
+		fmt.Println(T("quiz.no_questions"))
+		return nil
+	}
+
+	fmt.Println(T("quiz.intro", len(questions)))
+	fmt.Println()
+
+	answers := runQuiz(questions, os.Stdin, os.Stdout)
+	recordQuizAnswers(results, questions, answers, time.Now())
+
+	if err := saveQuizResults(results); err != nil {
+		fmt.Println(T("quiz.results_save_error", err))
+	}
+	printWeakTopics(categories, results)
+	return nil
+}
+
+// quizCategories resolves the --course flag, the detected course, or every course
+func quizCategories(course string) ([]string, error) {
+	if course == "" {
+		if detected := detectCurrentCourse(); detected != "" {
+			return []string{detected}, nil
+		}
+		return hintCategories, nil
+	}
+	if course == "all" {
+		return hintCategories, nil
+	}
+	if n, err := strconv.Atoi(course); err == nil && n >= 1 && n <= len(hintCategories) {
+		return []string{hintCategories[n-1]}, nil
+	}
+	for _, category := range hintCategories {
+		if strings.EqualFold(course, category) {
+			return []string{category}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown course %q", course)
+}
+
+// generateQuizQuestions builds up to count questions from the hints of the given categories
+func generateQuizQuestions(categories []string, count int, r *rand.Rand) []QuizQuestion {
+	var candidates []QuizQuestion
+	for _, category := range categories {
+		for _, hint := range localizedHints(category) {
+			candidates = append(candidates, questionsForHint(category, hint, r)...)
+		}
+	}
+	r.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	return pickQuestions(candidates, count)
+}
+
+// weakTopicQuestions builds questions only for topics answered below the weak threshold
+func weakTopicQuestions(categories []string, results *QuizResults, count int, r *rand.Rand) []QuizQuestion {
+	var candidates []QuizQuestion
+	for _, category := range categories {
+		for _, hint := range localizedHints(category) {
+			if score := results.topicScore(category, hint.ID); score.Asked > 0 && score.Accuracy() < weakTopicThreshold {
+				candidates = append(candidates, questionsForHint(category, hint, r)...)
+			}
+		}
+	}
+	r.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	return pickQuestions(candidates, count)
+}
+
+// pickQuestions takes count questions, preferring one question per topic before repeating topics
+func pickQuestions(candidates []QuizQuestion, count int) []QuizQuestion {
+	var picked, repeats []QuizQuestion
+	seen := map[string]bool{}
+	for _, question := range candidates {
+		if seen[question.HintID] {
+			repeats = append(repeats, question)
+			continue
+		}
+		seen[question.HintID] = true
+		picked = append(picked, question)
+	}
+	picked = append(picked, repeats...)
+	if count > 0 && len(picked) > count {
+		picked = picked[:count]
+	}
+	return picked
+}
+
+// questionsForHint generates every question the hint's content supports
+func questionsForHint(category string, hint Hint, r *rand.Rand) []QuizQuestion {
+	var questions []QuizQuestion
+
+	// Which topic does this description belong to?
+	var otherTitles []string
+	for _, c := range hintCategories {
+		for _, other := range localizedHints(c) {
+			if other.ID != hint.ID {
+				otherTitles = append(otherTitles, other.Title)
+			}
+		}
+	}
+	if len(otherTitles) >= 3 {
+		questions = append(questions, QuizQuestion{
+			HintID:   hint.ID,
+			Category: category,
+			Kind:     questionTopic,
+			Prompt:   T("quiz.prompt_topic"),
+			Context:  hint.Description,
+			Options:  multipleChoiceOptions(hint.Title, otherTitles, r),
+			Answer:   hint.Title,
+		})
+	}
+
+	// Which cmdlet completes the description, e.g. "____ filters objects based on conditions"?
+	if cmdlet := cmdletNamePattern.FindString(hint.Description); cmdlet != "" {
+		distractors := otherCmdlets(cmdlet)
+		if len(distractors) >= 3 {
+			questions = append(questions, QuizQuestion{
+				HintID:   hint.ID,
+				Category: category,
+				Kind:     questionCmdlet,
+				Prompt:   T("quiz.prompt_cmdlet"),
+				Context:  strings.ReplaceAll(hint.Description, cmdlet, "____"),
+				Options:  multipleChoiceOptions(cmdlet, distractors, r),
+				Answer:   cmdlet,
+			})
+		}
+	}
+
+	// Type the cmdlet missing from the example, blanked wherever it appears
+	if cmdlets := cmdletNamePattern.FindAllString(hint.Example, -1); len(cmdlets) > 0 {
+		cmdlet := cmdlets[r.Intn(len(cmdlets))]
+		questions = append(questions, QuizQuestion{
+			HintID:   hint.ID,
+			Category: category,
+			Kind:     questionBlank,
+			Prompt:   T("quiz.prompt_blank"),
+			Context:  strings.ReplaceAll(hint.Example, cmdlet, "____"),
+			Answer:   cmdlet,
+		})
+	}
+
+	return questions
+}
+
+// otherCmdlets returns every cmdlet mentioned in the hints except the given one
+func otherCmdlets(except string) []string {
+	seen := map[string]bool{strings.ToLower(except): true}
+	var cmdlets []string
+	for _, category := range hintCategories {
+		for _, hint := range powerShellHints[category] {
+			for _, cmdlet := range cmdletNamePattern.FindAllString(hint.Description+" "+hint.Example, -1) {
+				if !seen[strings.ToLower(cmdlet)] {
+					seen[strings.ToLower(cmdlet)] = true
+					cmdlets = append(cmdlets, cmdlet)
+				}
+			}
+		}
+	}
+	sort.Strings(cmdlets)
+	return cmdlets
+}
+
+// multipleChoiceOptions returns the answer and three distractors in random order
+func multipleChoiceOptions(answer string, distractors []string, r *rand.Rand) []string {
+	pool := append([]string{}, distractors...)
+	r.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	options := []string{answer}
+	for _, option := range pool {
+		if len(options) == 4 {
+			break
+		}
+		if option != answer {
+			options = append(options, option)
+		}
+	}
+	r.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	return options
+}
+
+// runQuiz asks each question on out, reads answers from in and reports which were correct.
+// Entering q stops the quiz early; unanswered questions are not recorded.
+func runQuiz(questions []QuizQuestion, in io.Reader, out io.Writer) []bool {
+	reader := bufio.NewReader(in)
+	var answers []bool
+
+	for i, question := range questions {
+		fmt.Fprintf(out, "❓ %s %s\n", T("quiz.question_number", i+1, len(questions)), question.Prompt)
+		fmt.Fprintf(out, "   %s\n", question.Context)
+		for j, option := range question.Options {
+			fmt.Fprintf(out, "   %d) %s\n", j+1, option)
+		}
+		fmt.Fprint(out, "👉 ")
+
+		line, err := reader.ReadString('\n')
+		input := strings.TrimSpace(line)
+		if strings.EqualFold(input, "q") || (err != nil && input == "") {
+			fmt.Fprintln(out)
+			break
+		}
+
+		correct := checkQuizAnswer(question, input)
+		answers = append(answers, correct)
+		if correct {
+			fmt.Fprintf(out, "%s\n\n", T("quiz.correct"))
+		} else {
+			fmt.Fprintf(out, "%s\n\n", T("quiz.incorrect", question.Answer))
+		}
+	}
+
+	score := 0
+	for _, correct := range answers {
+		if correct {
+			score++
+		}
+	}
+	fmt.Fprintln(out, T("quiz.score", score, len(answers)))
+	return answers
+}
+
+// checkQuizAnswer accepts an option number or the answer text, ignoring case
+func checkQuizAnswer(question QuizQuestion, input string) bool {
+	if n, err := strconv.Atoi(input); err == nil && len(question.Options) > 0 {
+		return n >= 1 && n <= len(question.Options) && question.Options[n-1] == question.Answer
+	}
+	return strings.EqualFold(strings.TrimSpace(input), question.Answer)
+}
+
+// recordQuizAnswers adds the answers to the per-course results
+func recordQuizAnswers(results *QuizResults, questions []QuizQuestion, answers []bool, taken time.Time) {
+	scores := map[string][2]int{}
+	for i, correct := range answers {
+		question := questions[i]
+		course := results.course(question.Category)
+		topic, ok := course.Topics[question.HintID]
+		if !ok {
+			topic = &TopicScore{}
+			course.Topics[question.HintID] = topic
+		}
+		topic.Asked++
+		score := scores[question.Category]
+		score[1]++
+		if correct {
+			topic.Correct++
+			score[0]++
+		}
+		scores[question.Category] = score
+	}
+
+	for category, score := range scores {
+		course := results.course(category)
+		course.Attempts++
+		course.LastScore = score[0]
+		course.LastTotal = score[1]
+		course.LastTaken = taken
+	}
+}
+
+// printWeakTopics lists the topics of the given courses answered below the weak threshold
+func printWeakTopics(categories []string, results *QuizResults) {
+	var weak []string
+	for _, category := range categories {
+		for _, hint := range localizedHints(category) {
+			score := results.topicScore(category, hint.ID)
+			if score.Asked > 0 && score.Accuracy() < weakTopicThreshold {
+				weak = append(weak, fmt.Sprintf("%s (%d/%d)", hint.Title, score.Correct, score.Asked))
+			}
+		}
+	}
+	if len(weak) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(T("quiz.weak_topics"))
+	for _, topic := range weak {
+		fmt.Printf("   • %s\n", topic)
+	}
+	fmt.Println(T("quiz.revisit_tip"))
+}
+
+func (r *QuizResults) course(category string) *CourseQuizResults {
+	if r.Courses == nil {
+		r.Courses = map[string]*CourseQuizResults{}
+	}
+	course, ok := r.Courses[category]
+	if !ok {
+		course = &CourseQuizResults{}
+		r.Courses[category] = course
+	}
+	if course.Topics == nil {
+		course.Topics = map[string]*TopicScore{}
+	}
+	return course
+}
+
+func (r *QuizResults) topicScore(category, hintID string) TopicScore {
+	if course, ok := r.Courses[category]; ok {
+		if score, ok := course.Topics[hintID]; ok {
+			return *score
+		}
+	}
+	return TopicScore{}
+}
+
+// quizResultsPath returns the file quiz results are stored in
+func quizResultsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "quiz-results.json"), nil
+}
+
+func loadQuizResults() (*QuizResults, error) {
+	results := &QuizResults{Courses: map[string]*CourseQuizResults{}}
+	path, err := quizResultsPath()
+	if err != nil {
+		return results, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return results, nil
+	}
+	if err != nil {
+		return results, err
+	}
+	if err := json.Unmarshal(data, results); err != nil {
+		return &QuizResults{Courses: map[string]*CourseQuizResults{}}, err
+	}
+	return results, nil
+}
+
+func saveQuizResults(results *QuizResults) error {
+	path, err := quizResultsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileWithDirs(path, data)
+}
+
+func init() {
+	quizCmd.Flags().StringVarP(&quizCourse, "course", "c", "", "Course to quiz on: 1-4, a name such as 'pipelines', or 'all'")
+	quizCmd.Flags().IntVarP(&quizQuestions, "questions", "n", 5, "Number of questions to ask")
+	quizCmd.Flags().BoolVar(&quizWeakOnly, "weak", false, "Only ask about topics you previously answered poorly")
+	rootCmd.AddCommand(quizCmd)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateQuizQuestions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	questions := generateQuizQuestions([]string{"pipelines"}, 10, r)
+	if len(questions) == 0 {
+		t.Fatal("Expected questions for the pipelines course")
+	}
+
+	for _, q := range questions {
+		if q.Category != "pipelines" {
+			t.Errorf("Question for wrong category: %s", q.Category)
+		}
+		switch q.Kind {
+		case questionTopic, questionCmdlet:
+			if len(q.Options) != 4 {
+				t.Errorf("Expected 4 options, got %v", q.Options)
+			}
+			if !checkQuizAnswer(q, q.Answer) {
+				t.Errorf("Answer %q not accepted for %q", q.Answer, q.Prompt)
+			}
+		case questionBlank:
+			if !strings.Contains(q.Context, "____") {
+				t.Errorf("Blank question does not hide the answer: %q", q.Context)
+			}
+		}
+	}
+
+	if got := generateQuizQuestions(hintCategories, 3, r); len(got) != 3 {
+		t.Errorf("Expected 3 questions, got %d", len(got))
+	}
+}
+
+func TestQuizQuestionsHideTheAnswer(t *testing.T) {
+	// Examples such as fundamentals.conditionals use the same cmdlet more than once
+	for _, category := range hintCategories {
+		for _, hint := range localizedHints(category) {
+			for seed := int64(0); seed < 5; seed++ {
+				for _, q := range questionsForHint(category, hint, rand.New(rand.NewSource(seed))) {
+					if q.Kind != questionTopic && strings.Contains(q.Context, q.Answer) {
+						t.Errorf("%s: %s question shows its answer %q in %q", hint.ID, q.Kind, q.Answer, q.Context)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRunQuizRecordsResults(t *testing.T) {
+	questions := []QuizQuestion{
+		{HintID: "pipelines.filtering", Category: "pipelines", Kind: questionBlank, Context: "Get-Service | ____ Status -eq \"Running\"", Answer: "Where-Object"},
+		{HintID: "pipelines.basics", Category: "pipelines", Kind: questionTopic, Options: []string{"Pipeline Basics", "Error Handling"}, Answer: "Pipeline Basics"},
+		{HintID: "pipelines.basics", Category: "pipelines", Kind: questionTopic, Options: []string{"Pipeline Basics"}, Answer: "Pipeline Basics"},
+	}
+
+	var out bytes.Buffer
+	answers := runQuiz(questions, strings.NewReader("where-object\n2\nq\n"), &out)
+	if len(answers) != 2 || !answers[0] || answers[1] {
+		t.Fatalf("Unexpected answers: %v", answers)
+	}
+
+	results := &QuizResults{}
+	recordQuizAnswers(results, questions, answers, time.Now())
+	course := results.Courses["pipelines"]
+	if course == nil || course.Attempts != 1 || course.LastScore != 1 || course.LastTotal != 2 {
+		t.Fatalf("Unexpected course results: %+v", course)
+	}
+	if score := results.topicScore("pipelines", "pipelines.basics"); score.Asked != 1 || score.Correct != 0 {
+		t.Errorf("Unexpected topic score: %+v", score)
+	}
+}
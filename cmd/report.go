@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Output formats of validate
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatJUnit = "junit"
+	formatSARIF = "sarif"
+)
+
+// validateFormats lists the formats accepted by validate --format
+var validateFormats = []string{formatText, formatJSON, formatJUnit, formatSARIF}
+
+// validFormat reports whether format is one of validateFormats
+func validFormat(format string) bool {
+	for _, f := range validateFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationReport is everything a validate run produced, for the report formats
+type ValidationReport struct {
+	Files []FileResult
+	// TestsRun is set when --tests was given; TestsError explains why no tests could run
+	TestsRun   bool
+	Tests      []TestResult
+	TestsError string
+	// Matrix is set when more than one PowerShell executable was configured
+	Matrix *CompatibilityMatrix
+}
+
+// reportSchemaURL identifies the schema of the JSON report, kept in docs/validate-report.schema.json
+const reportSchemaURL = "https://raw.githubusercontent.com/sup3r7-fabio/gh-pwsh-skills/main/docs/validate-report.schema.json"
+
+// reportSchemaVersion is bumped whenever the JSON report changes incompatibly
+const reportSchemaVersion = 1
+
+type jsonReport struct {
+	Schema  string               `json:"$schema"`
+	Version int                  `json:"version"`
+	Tool    jsonTool             `json:"tool"`
+	Valid   bool                 `json:"valid"`
+	Summary jsonSummary          `json:"summary"`
+	Files   []FileResult         `json:"files"`
+	Tests   *jsonTestsRun        `json:"tests,omitempty"`
+	Matrix  *CompatibilityMatrix `json:"matrix,omitempty"`
+}
+
+type jsonTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type jsonSummary struct {
+	Files       int `json:"files"`
+	FailedFiles int `json:"failedFiles"`
+	Errors      int `json:"errors"`
+	Warnings    int `json:"warnings"`
+	Info        int `json:"info"`
+	Tests       int `json:"tests"`
+	FailedTests int `json:"failedTests"`
+}
+
+type jsonTestsRun struct {
+	Error   string       `json:"error,omitempty"`
+	Results []TestResult `json:"results"`
+}
+
+// writeJSON writes the validation report in the native JSON format
+func writeJSON(w io.Writer, report ValidationReport) error {
+	out := jsonReport{
+		Schema:  reportSchemaURL,
+		Version: reportSchemaVersion,
+		Tool:    jsonTool{Name: "pwsh-skills", Version: version},
+		Valid:   true,
+		Files:   report.Files,
+	}
+	if out.Files == nil {
+		out.Files = []FileResult{}
+	}
+
+	for _, file := range report.Files {
+		out.Summary.Files++
+		if !file.Valid {
+			out.Summary.FailedFiles++
+			out.Valid = false
+		}
+		for _, finding := range file.Findings {
+			switch finding.Severity {
+			case severityError:
+				out.Summary.Errors++
+			case severityWarning:
+				out.Summary.Warnings++
+			default:
+				out.Summary.Info++
+			}
+		}
+	}
+
+	if report.Matrix != nil {
+		out.Matrix = report.Matrix
+		if len(report.Matrix.failedFiles()) > 0 {
+			out.Valid = false
+		}
+	}
+
+	if report.TestsRun {
+		out.Tests = &jsonTestsRun{Error: report.TestsError, Results: report.Tests}
+		if out.Tests.Results == nil {
+			out.Tests.Results = []TestResult{}
+		}
+		if report.TestsError != "" {
+			out.Valid = false
+		}
+		for _, test := range report.Tests {
+			out.Summary.Tests++
+			if testFailed(test) {
+				out.Summary.FailedTests++
+				out.Valid = false
+			}
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
@@ -15,31 +15,62 @@ var (
 
 var rootCmd = &cobra.Command{
 Use:   "pwsh-skills",
+// Errors are printed once by main
+SilenceErrors: true,
 Short: "Interactive PowerShell GitHub Skills course assistant",
 Long: `A GitHub CLI extension that enhances your PowerShell GitHub Skills learning experience.
 
-Available Commands:
-  status     Show current progress across all PowerShell courses
-  hint       Get contextual hints for the current step  
-  validate   Validate your PowerShell solution locally
-  next       Navigate to the next PowerShell course
-  back       Navigate back to the previous PowerShell course
+Use "gh pwsh-skills [command] --help" for more information about a command.
 
-Use "gh pwsh-skills [command] --help" for more information about a command.`,
+Messages are shown in the language from --lang, LC_ALL, LC_MESSAGES or LANG
+(en, de, es, pt), falling back to English.`,
+PersistentPreRun: func(cmd *cobra.Command, args []string) {
+setLocale(langFlag)
+},
 Run: func(cmd *cobra.Command, args []string) {
-fmt.Println("🚀 Welcome to PowerShell GitHub Skills!")
+fmt.Println(T("root.welcome"))
 fmt.Println()
-fmt.Println("📚 Available Commands:")
-fmt.Println("  status     📊 Show your progress across all courses")
-fmt.Println("  hint       💡 Get contextual hints for your current step")
-fmt.Println("  validate   🧪 Test your PowerShell code locally")
-fmt.Println("  next       ⏭️  Move to the next course")
-fmt.Println("  back       ⏮️  Go back to the previous course")
+fmt.Println(T("root.available_commands"))
+printCommandList(cmd)
 fmt.Println()
-fmt.Println("💡 Start with 'gh pwsh-skills status' to see your current progress!")
+fmt.Println(T("root.get_started"))
 },
 }
 
+// commandIcons are shown before each command on the welcome screen, with the spacing that
+// lines up the descriptions after them
+var commandIcons = map[string]string{
+	"status":   "📊 ",
+	"hint":     "💡 ",
+	"validate": "🧪 ",
+	"next":     "⏭️  ",
+	"back":     "⏮️  ",
+	"quiz":     "🧠 ",
+	"test":     "✅ ",
+	"config":   "⚙️  ",
+	"hooks":    "🪝 ",
+	"i18n":     "🌐 ",
+}
+
+// printCommandList lists the registered subcommands of root for the welcome screen, each with
+// the root.command.<name> message or, without one, its short description
+func printCommandList(root *cobra.Command) {
+	for _, command := range root.Commands() {
+		if !command.IsAvailableCommand() {
+			continue
+		}
+		description := T("root.command." + command.Name())
+		if description == "root.command."+command.Name() {
+			description = command.Short
+		}
+		icon, ok := commandIcons[command.Name()]
+		if !ok {
+			icon = "   "
+		}
+		fmt.Printf("  %-10s %s%s\n", command.Name(), icon, description)
+	}
+}
+
 // SetVersionInfo sets the version information from main
 func SetVersionInfo(v, c, d, b string) {
 	version = v
@@ -51,11 +82,16 @@ func SetVersionInfo(v, c, d, b string) {
 	rootCmd.SetVersionTemplate(fmt.Sprintf("PowerShell GitHub Skills CLI Extension %s\nBuilt: %s\nCommit: %s\nBuilt by: %s\n", version, date, commit, builtBy))
 }
 
+// Execute runs the CLI. Use ExitCode to turn the returned error into the process exit code.
 func Execute() error {
+	// Shut down the PowerShell workers however the command finishes
+	defer stopPowerShellWorkers()
+	withExitCodes(rootCmd)
 	return rootCmd.Execute()
 }
 
 func init() {
 	// Initial version template - will be updated when SetVersionInfo is called
 	rootCmd.SetVersionTemplate("PowerShell GitHub Skills CLI Extension {{.Version}}\n")
+	rootCmd.PersistentFlags().StringVar(&langFlag, "lang", "", "Language for messages and hints (en, de, es, pt)")
 }
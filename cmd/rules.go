@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Rule describes a check that reports findings, for reports that carry rule metadata.
+// Rules with a check are run by runRules over a file's syntax tree; the others report
+// findings from the parser, module checks or suppressions. Rules with a fix can rewrite
+// the code they report with validate --fix.
+type Rule struct {
+	ID          string
+	Name        string
+	Description string
+	HelpURI     string
+	Category    string
+	Severity    string
+	check       ruleCheck
+	fix         ruleFix
+}
+
+// ruleCheck inspects a parsed file and reports every violation of its rule with the extent
+// of the offending code and a message
+type ruleCheck func(tree *SyntaxTree, report func(extent Extent, message string))
+
+// ruleFix returns the edits that resolve a finding of its rule, or none when the code cannot
+// be fixed safely
+type ruleFix func(source *sourceText, tree *SyntaxTree, finding Finding) []TextEdit
+
+// builtinRules are the checks built into validate
+var builtinRules = []Rule{
+	{
+		ID:          "SY001",
+		Name:        "SyntaxError",
+		Description: "The PowerShell parser could not parse the script.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.core/about/about_parsing",
+		Category:    categorySyntax,
+		Severity:    severityError,
+	},
+	{
+		ID:          "SY002",
+		Name:        "InvalidXml",
+		Description: "The formatting or type file (.ps1xml) is not well-formed XML.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.core/about/about_format.ps1xml",
+		Category:    categorySyntax,
+		Severity:    severityError,
+	},
+	{
+		ID:          "MM001",
+		Name:        "MissingManifestKey",
+		Description: "The module manifest does not set a key that Test-ModuleManifest or the PowerShell Gallery requires.",
+		HelpURI:     "https://learn.microsoft.com/powershell/scripting/developer/module/how-to-write-a-powershell-module-manifest",
+		Category:    categoryModule,
+		Severity:    severityError,
+	},
+	{
+		ID:          "MM002",
+		Name:        "ManifestFileNotFound",
+		Description: "A file the module manifest loads, such as its RootModule, does not exist.",
+		HelpURI:     "https://learn.microsoft.com/powershell/scripting/developer/module/how-to-write-a-powershell-module-manifest",
+		Category:    categoryModule,
+		Severity:    severityError,
+	},
+	{
+		ID:          "MM003",
+		Name:        "ExportedFunctionNotDefined",
+		Description: "FunctionsToExport lists a function the module does not define.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.core/new-modulemanifest#-functionstoexport",
+		Category:    categoryModule,
+		Severity:    severityWarning,
+	},
+	{
+		ID:          "MM004",
+		Name:        "FunctionNotExported",
+		Description: "The root module defines a function that FunctionsToExport does not list, so it stays private.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.core/new-modulemanifest#-functionstoexport",
+		Category:    categoryModule,
+		Severity:    severityInfo,
+	},
+	{
+		ID:          "MM005",
+		Name:        "InvalidManifestVersion",
+		Description: "A version in the module manifest is not a valid version such as 1.2.0, or the prerelease label has characters other than letters, digits and hyphens.",
+		HelpURI:     "https://learn.microsoft.com/powershell/gallery/concepts/module-prerelease-support",
+		Category:    categoryModule,
+		Severity:    severityError,
+	},
+	{
+		ID:          "MM006",
+		Name:        "ModuleImportFailed",
+		Description: "Importing the module in a fresh PowerShell session raised an error.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.core/import-module",
+		Category:    categoryModule,
+		Severity:    severityError,
+	},
+	{
+		ID:          "MM007",
+		Name:        "ModuleImportWarning",
+		Description: "Importing the module in a fresh PowerShell session raised a warning, such as for unapproved verbs.",
+		HelpURI:     "https://learn.microsoft.com/powershell/scripting/developer/cmdlet/approved-verbs-for-windows-powershell-commands",
+		Category:    categoryModule,
+		Severity:    severityWarning,
+	},
+	{
+		ID:          "CP001",
+		Name:        "WindowsOnlyCmdlet",
+		Description: "The cmdlet is only available, or only fully functional, on Windows.",
+		HelpURI:     "https://learn.microsoft.com/powershell/scripting/whats-new/differences-from-windows-powershell",
+		Category:    categoryCrossPlatform,
+		Severity:    severityWarning,
+		check:       checkWindowsOnlyCmdlets,
+	},
+	{
+		ID:          "CP002",
+		Name:        "HardcodedWindowsPath",
+		Description: "A hardcoded Windows path will not resolve on Linux or macOS; build paths with Join-Path.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.management/join-path",
+		Category:    categoryCrossPlatform,
+		Severity:    severityWarning,
+		check:       checkWindowsPaths,
+		fix:         fixWindowsPath,
+	},
+	{
+		ID:          "MV001",
+		Name:        "RequiresVersionTooLow",
+		Description: "The script uses syntax or a cmdlet that needs a newer PowerShell than its #Requires -Version declares, so it fails on the versions it claims to support.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.core/about/about_requires",
+		Category:    categoryCrossPlatform,
+		Severity:    severityWarning,
+		check:       checkRequiredVersion,
+	},
+	{
+		ID:          "MV002",
+		Name:        "NeedsNewerPowerShell",
+		Description: "The script uses syntax or a cmdlet that Windows PowerShell 5.1 does not have; declare the version it needs with #Requires -Version.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.core/about/about_requires",
+		Category:    categoryCrossPlatform,
+		Severity:    severityInfo,
+		check:       checkMinimumVersion,
+	},
+	{
+		ID:          "BP001",
+		Name:        "MissingCmdletBinding",
+		Description: "Functions should use [CmdletBinding()] to become advanced functions with common parameters.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.core/about/about_functions_cmdletbindingattribute",
+		Category:    categoryBestPractices,
+		Severity:    severityInfo,
+		check:       checkCmdletBinding,
+		fix:         fixCmdletBinding,
+	},
+	{
+		ID:          "BP002",
+		Name:        "AvoidWriteHost",
+		Description: "Write-Host bypasses the pipeline; use Write-Output for data and Write-Verbose or Write-Information for messages.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.utility/write-output",
+		Category:    categoryBestPractices,
+		Severity:    severityInfo,
+		check:       checkWriteHost,
+	},
+	{
+		ID:          "BP003",
+		Name:        "MissingParamBlock",
+		Description: "Functions should declare their parameters in a param() block.",
+		HelpURI:     "https://learn.microsoft.com/powershell/module/microsoft.powershell.core/about/about_functions_advanced_parameters",
+		Category:    categoryBestPractices,
+		Severity:    severityInfo,
+		check:       checkParamBlock,
+		fix:         fixParamBlock,
+	},
+	{
+		ID:          "BP004",
+		Name:        "AvoidAlias",
+		Description: "Aliases make scripts harder to read and may not exist in every session; use the full command name.",
+		HelpURI:     "https://learn.microsoft.com/powershell/scripting/learn/shell/using-aliases",
+		Category:    categoryBestPractices,
+		Severity:    severityInfo,
+		check:       checkAliases,
+		fix:         fixAlias,
+	},
+	{
+		ID:          "SP001",
+		Name:        "UnusedSuppression",
+		Description: "A suppression comment or attribute does not silence any finding and can be removed.",
+		HelpURI:     "https://github.com/sup3r7-fabio/gh-pwsh-skills#suppressing-findings",
+		Category:    categoryBestPractices,
+		Severity:    severityWarning,
+	},
+}
+
+// runRules runs the built-in rules of a category over a parsed file and returns their
+// findings ordered by location
+func runRules(tree *SyntaxTree, category string) []Finding {
+	findings := []Finding{}
+	for _, rule := range builtinRules {
+		if rule.check == nil || rule.Category != category {
+			continue
+		}
+		rule.check(tree, func(extent Extent, message string) {
+			findings = append(findings, rule.finding(tree.File, extent, message))
+		})
+	}
+	sortFindings(findings)
+	return findings
+}
+
+// builtinRule returns the built-in rule with the given ID
+func builtinRule(id string) Rule {
+	for _, rule := range builtinRules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	panic("unknown built-in rule " + id)
+}
+
+// finding reports a violation of the rule in file at extent
+func (rule Rule) finding(file string, extent Extent, message string) Finding {
+	return Finding{
+		RuleID: rule.ID, Severity: rule.Severity, Category: rule.Category, File: file,
+		Line: extent.Line, Column: extent.Column, EndLine: extent.EndLine, EndColumn: extent.EndColumn,
+		Message: message, Source: sourceBuiltin,
+	}
+}
+
+// windowsOnlyCmdlets are cmdlets that are missing, or only partly work, outside Windows
+var windowsOnlyCmdlets = []string{
+	"Get-WmiObject",
+	"Get-Service", // Available on Linux but with limited functionality
+	"New-Service",
+	"Set-Service",
+	"Get-EventLog",
+	"Get-WindowsFeature",
+}
+
+// commandAliases are built-in aliases defined on every platform, by lowercase name. Aliases
+// such as ls, cat or sort are left out: on Linux and macOS they run native commands instead.
+var commandAliases = map[string]string{
+	"%":       "ForEach-Object",
+	"?":       "Where-Object",
+	"foreach": "ForEach-Object",
+	"where":   "Where-Object",
+	"select":  "Select-Object",
+	"measure": "Measure-Object",
+	"group":   "Group-Object",
+	"gci":     "Get-ChildItem",
+	"dir":     "Get-ChildItem",
+	"gc":      "Get-Content",
+	"gi":      "Get-Item",
+	"gp":      "Get-ItemProperty",
+	"gm":      "Get-Member",
+	"gcm":     "Get-Command",
+	"gal":     "Get-Alias",
+	"ft":      "Format-Table",
+	"fl":      "Format-List",
+	"iwr":     "Invoke-WebRequest",
+	"irm":     "Invoke-RestMethod",
+	"iex":     "Invoke-Expression",
+	"icm":     "Invoke-Command",
+	"ipmo":    "Import-Module",
+	"gmo":     "Get-Module",
+	"cd":      "Set-Location",
+	"sl":      "Set-Location",
+	"gl":      "Get-Location",
+	"echo":    "Write-Output",
+	"ni":      "New-Item",
+	"ri":      "Remove-Item",
+	"del":     "Remove-Item",
+	"rni":     "Rename-Item",
+	"mi":      "Move-Item",
+	"move":    "Move-Item",
+	"cpi":     "Copy-Item",
+	"copy":    "Copy-Item",
+	"gv":      "Get-Variable",
+	"sv":      "Set-Variable",
+	"nv":      "New-Variable",
+	"rvpa":    "Resolve-Path",
+	"ii":      "Invoke-Item",
+	"gps":     "Get-Process",
+	"gjb":     "Get-Job",
+	"sajb":    "Start-Job",
+	"rcjb":    "Receive-Job",
+	"wjb":     "Wait-Job",
+	"sls":     "Select-String",
+}
+
+// windowsPathPattern matches a drive letter path such as C:\ or a UNC path such as \\server
+var windowsPathPattern = regexp.MustCompile(`(?i)\b[a-z]:\\|(^|\s)\\\\\w`)
+
+// checkWindowsOnlyCmdlets reports invocations of Windows-only cmdlets, including
+// module-qualified ones such as Microsoft.PowerShell.Management\Get-Service and those
+// added by windowsOnlyCmdlets in .pwsh-skills.yml
+func checkWindowsOnlyCmdlets(tree *SyntaxTree, report func(Extent, string)) {
+	cmdlets := append(append([]string{}, windowsOnlyCmdlets...), projectConfig.WindowsOnlyCmdlets...)
+	for _, command := range tree.Commands {
+		for _, cmdlet := range cmdlets {
+			if strings.EqualFold(command.unqualifiedName(), cmdlet) {
+				report(command.Extent, T("validate.cmdlet_not_portable", cmdlet))
+			}
+		}
+	}
+}
+
+// checkWindowsPaths reports string literals and bare arguments holding a Windows path;
+// comments are separate tokens and never match
+func checkWindowsPaths(tree *SyntaxTree, report func(Extent, string)) {
+	for _, token := range tree.Tokens {
+		if token.Value != "" && windowsPathPattern.MatchString(token.Value) {
+			report(token.Extent, T("validate.windows_paths"))
+		}
+	}
+}
+
+// checkCmdletBinding reports functions with a param() block but no [CmdletBinding()]
+func checkCmdletBinding(tree *SyntaxTree, report func(Extent, string)) {
+	for _, function := range tree.Functions {
+		if function.HasParamBlock && !function.HasCmdletBinding {
+			report(function.Extent, T("validate.suggest_cmdletbinding"))
+		}
+	}
+}
+
+// checkWriteHost reports calls to Write-Host
+func checkWriteHost(tree *SyntaxTree, report func(Extent, string)) {
+	for _, command := range tree.Commands {
+		if strings.EqualFold(command.unqualifiedName(), "Write-Host") {
+			report(command.Extent, T("validate.suggest_write_output"))
+		}
+	}
+}
+
+// checkAliases reports commands invoked by a built-in alias instead of their full name
+func checkAliases(tree *SyntaxTree, report func(Extent, string)) {
+	for _, command := range tree.Commands {
+		if name, ok := commandAliases[strings.ToLower(command.Name)]; ok {
+			report(command.Extent, T("validate.suggest_full_name", name, command.Name))
+		}
+	}
+}
+
+// checkParamBlock reports functions that do not declare their parameters in a param() block
+func checkParamBlock(tree *SyntaxTree, report func(Extent, string)) {
+	for _, function := range tree.Functions {
+		if !function.HasParamBlock {
+			report(function.Extent, T("validate.suggest_param_block"))
+		}
+	}
+}
+
+// analyzerRuleHelpBase is where the PSScriptAnalyzer rule documentation lives
+const analyzerRuleHelpBase = "https://learn.microsoft.com/powershell/utility-modules/psscriptanalyzer/rules/"
+
+// ruleForFinding returns the rule that reported a finding. PSScriptAnalyzer rules are not
+// registered, so their metadata is derived from the rule name.
+func ruleForFinding(finding Finding) Rule {
+	for _, rule := range builtinRules {
+		if rule.ID == finding.RuleID {
+			return rule
+		}
+	}
+	rule := Rule{ID: finding.RuleID, Name: finding.RuleID, Category: finding.Category, Severity: finding.Severity}
+	if finding.Source == sourceAnalyzer {
+		rule.Description = "PSScriptAnalyzer rule " + finding.RuleID + "."
+		rule.HelpURI = analyzerRuleHelpBase + strings.ToLower(strings.TrimPrefix(finding.RuleID, "PS"))
+	}
+	return rule
+}
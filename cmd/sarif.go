@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SARIF 2.1.0 report, limited to the properties validate fills in.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name,omitempty"`
+	ShortDescription     *sarifMessage      `json:"shortDescription,omitempty"`
+	HelpURI              string             `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifProperties    `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// sarifLevels maps finding severities to SARIF result levels
+var sarifLevels = map[string]string{
+	severityError:   "error",
+	severityWarning: "warning",
+	severityInfo:    "note",
+}
+
+// writeSARIF writes the validation results as a SARIF 2.1.0 log
+func writeSARIF(w io.Writer, results []FileResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(buildSARIF(results))
+}
+
+// buildSARIF converts validation results into a SARIF log with a single run. Every built-in
+// rule is listed, plus the PSScriptAnalyzer rules that reported findings. Suppressed findings
+// are included with an in-source suppression, so code scanning shows them as dismissed.
+// Files are located relative to the repository root, however deep the current directory.
+func buildSARIF(results []FileResult) sarifLog {
+	prefix := sarifRootPrefix()
+	rules := append([]Rule{}, builtinRules...)
+	ruleIndex := map[string]int{}
+	for i, rule := range rules {
+		ruleIndex[rule.ID] = i
+	}
+	var extra []Rule
+	for _, result := range results {
+		for _, finding := range append(append([]Finding{}, result.Findings...), result.Suppressed...) {
+			if _, ok := ruleIndex[finding.RuleID]; !ok {
+				ruleIndex[finding.RuleID] = -1
+				extra = append(extra, ruleForFinding(finding))
+			}
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].ID < extra[j].ID })
+	for _, rule := range extra {
+		ruleIndex[rule.ID] = len(rules)
+		rules = append(rules, rule)
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "pwsh-skills",
+			Version:        version,
+			InformationURI: "https://github.com/sup3r7-fabio/gh-pwsh-skills",
+		}},
+		Invocations: []sarifInvocation{{ExecutionSuccessful: true}},
+		Results:     []sarifResult{},
+	}
+	for _, rule := range rules {
+		sr := sarifRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			HelpURI:              rule.HelpURI,
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevels[rule.Severity]},
+			Properties:           sarifProperties{Tags: []string{rule.Category}},
+		}
+		if rule.Description != "" {
+			sr.ShortDescription = &sarifMessage{Text: rule.Description}
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sr)
+	}
+
+	for _, result := range results {
+		file := filepath.Join(prefix, result.File)
+		for _, check := range result.Checks {
+			if check.Problem == "" {
+				continue
+			}
+			run.Invocations[0].ToolExecutionNotifications = append(run.Invocations[0].ToolExecutionNotifications, sarifNotification{
+				Level:     "error",
+				Message:   sarifMessage{Text: check.Problem},
+				Locations: []sarifLocation{sarifFileLocation(file, Finding{})},
+			})
+			run.Invocations[0].ExecutionSuccessful = false
+		}
+		for _, finding := range result.Findings {
+			run.Results = append(run.Results, sarifFindingResult(file, finding, ruleIndex))
+		}
+		for _, finding := range result.Suppressed {
+			sr := sarifFindingResult(file, finding, ruleIndex)
+			sr.Suppressions = []sarifSuppression{{Kind: "inSource"}}
+			run.Results = append(run.Results, sr)
+		}
+	}
+
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+// sarifFindingResult converts a finding into a SARIF result
+func sarifFindingResult(file string, finding Finding, ruleIndex map[string]int) sarifResult {
+	return sarifResult{
+		RuleID:    finding.RuleID,
+		RuleIndex: ruleIndex[finding.RuleID],
+		Level:     sarifLevels[finding.Severity],
+		Message:   sarifMessage{Text: finding.Message},
+		Locations: []sarifLocation{sarifFileLocation(file, finding)},
+	}
+}
+
+// sarifFileLocation locates a finding by its path relative to the repository root and, when
+// known, its region
+func sarifFileLocation(file string, finding Finding) sarifLocation {
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: sarifURI(file), URIBaseID: "%SRCROOT%"},
+	}}
+	if finding.Line > 0 {
+		region := &sarifRegion{StartLine: finding.Line, StartColumn: finding.Column}
+		if finding.EndLine >= finding.Line && finding.EndColumn > 0 {
+			region.EndLine = finding.EndLine
+			region.EndColumn = finding.EndColumn
+		}
+		location.PhysicalLocation.Region = region
+	}
+	return location
+}
+
+// sarifRootPrefix returns the path of the current directory relative to the root of the
+// git repository, such as course-2-pipelines-filtering/, which %SRCROOT% stands for in
+// code scanning. Outside a repository files stay relative to the current directory.
+func sarifRootPrefix() string {
+	output, err := runGit("rev-parse", "--show-prefix")
+	if err != nil {
+		return ""
+	}
+	return filepath.FromSlash(strings.TrimSpace(string(output)))
+}
+
+// sarifURI turns a relative file path into a relative URI reference with forward slashes
+func sarifURI(file string) string {
+	return (&url.URL{Path: filepath.ToSlash(filepath.Clean(file))}).String()
+}
@@ -14,46 +14,46 @@ var statusCmd = &cobra.Command{
 Use:   "status",
 Short: "Show current progress across all PowerShell courses",
 Long:  `Display your current progress in all PowerShell GitHub Skills courses`,
-Run: func(cmd *cobra.Command, args []string) {
-showStatus()
+RunE: func(cmd *cobra.Command, args []string) error {
+return showStatus()
 },
 }
 
-func showStatus() {
-fmt.Println("📍 PowerShell GitHub Skills - Progress Status")
+func showStatus() error {
+fmt.Println(T("status.title"))
 fmt.Println("=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=")
 
 // Check if we''re in a git repository
 if !isGitRepo() {
-fmt.Println("❌ Not in a git repository. Please run from your PowerShell Skills course directory.")
-return
+fmt.Println(T("common.not_git_repo"))
+return reportedError(exitNotInCourse, "not in a git repository")
 }
 
 // Get repository information
 repoInfo, err := getRepoInfo()
 if err != nil {
-fmt.Printf("❌ Error getting repository info: %v\n", err)
-return
+fmt.Println(T("status.repo_error", err))
+return reported(exitError(exitEnvironment, err))
 }
 
-fmt.Printf("📂 Repository: %s\n", repoInfo)
+fmt.Println(T("status.repository", repoInfo))
 
 // Detect courses and progress
 courses := DetectAvailableCourses()
 if len(courses) == 0 {
-fmt.Println("❌ No PowerShell Skills courses detected in this repository.")
-return
+fmt.Println(T("status.no_courses"))
+return reportedError(exitNotInCourse, "no PowerShell courses found")
 }
 
-fmt.Println("\n🎯 Course Progress:")
+fmt.Println("\n" + T("status.course_progress"))
 for _, course := range courses {
 displayCourseProgress(course)
 }
 
 // Overall progress
 completed, total, percentage := GetCourseProgressSummary()
-fmt.Printf("\n🏆 Overall Progress: %d/%d courses completed (%.1f%%)\n", 
-completed, total, percentage)
+fmt.Println("\n" + T("status.overall_progress", completed, total, percentage))
+return nil
 }
 
 func getRepoInfo() (string, error) {
@@ -92,11 +92,11 @@ progressBar += "░"
 }
 
 fmt.Printf("  %s %s\n", status, course.Name)
-fmt.Printf("     Progress: [%s] %d/%d steps\n", progressBar, course.CurrentStep, course.TotalSteps)
+fmt.Println("     " + T("status.step_progress", progressBar, course.CurrentStep, course.TotalSteps))
 
 if !course.Completed {
 estimatedTime := (course.TotalSteps - course.CurrentStep) * 10
-fmt.Printf("     ⏱️  Estimated time remaining: %d minutes\n", estimatedTime)
+fmt.Println("     " + T("status.time_remaining", estimatedTime))
 }
 fmt.Println()
 }
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Suppression comments silence findings of the listed rules, or of every rule when none
+// are listed. A reason can follow the rules after "--".
+//
+//	# pwsh-skills-disable-next-line CP001 -- course 4 manages services on Windows
+//	Get-Service # pwsh-skills-disable-line CP001
+//	# pwsh-skills-disable CP001, CP002
+//	...
+//	# pwsh-skills-enable CP001, CP002
+//	# pwsh-skills-disable-file BP002
+//
+// A disable without a matching enable lasts until the end of the file.
+const suppressionPrefix = "pwsh-skills-"
+
+// Suppression directives
+const (
+	directiveDisableNextLine = "disable-next-line"
+	directiveDisableLine     = "disable-line"
+	directiveDisable         = "disable"
+	directiveEnable          = "enable"
+	directiveDisableFile     = "disable-file"
+)
+
+// Suppression silences findings of some rules within part of a file
+type Suppression struct {
+	Rules  []string // the rule IDs, or none for every rule
+	Scope  Extent   // the code covered; the zero Extent covers the whole file
+	Source Extent   // where the comment or attribute is written
+	used   bool
+}
+
+// findSuppressions collects the suppression comments and the [SuppressMessage()] attributes
+// naming built-in rules; PSScriptAnalyzer applies the attributes for its own rules itself
+func findSuppressions(tree *SyntaxTree) []*Suppression {
+	var suppressions, open []*Suppression
+	for _, token := range tree.Tokens {
+		if token.Kind != "Comment" {
+			continue
+		}
+		directive, rules, ok := parseSuppressionComment(token.Text)
+		if !ok {
+			continue
+		}
+
+		suppression := &Suppression{Rules: rules, Source: token.Extent}
+		switch directive {
+		case directiveDisableNextLine:
+			suppression.Scope = lineExtent(token.EndLine+1, token.EndLine+1)
+		case directiveDisableLine:
+			suppression.Scope = lineExtent(token.Line, token.Line)
+		case directiveDisable:
+			suppression.Scope = lineExtent(token.Line, math.MaxInt32)
+			open = append(open, suppression)
+		case directiveEnable:
+			open = closeSuppressions(open, rules, token.Extent)
+			continue
+		case directiveDisableFile:
+			// The zero Scope covers the whole file
+		}
+		suppressions = append(suppressions, suppression)
+	}
+
+	for _, attribute := range tree.Suppressions {
+		if isBuiltinRule(attribute.Rule) {
+			suppressions = append(suppressions, &Suppression{
+				Rules: []string{attribute.Rule}, Scope: attribute.Scope, Source: attribute.Extent,
+			})
+		}
+	}
+	return suppressions
+}
+
+// parseSuppressionComment returns the directive and rule IDs of a suppression comment
+func parseSuppressionComment(comment string) (string, []string, bool) {
+	text := strings.TrimPrefix(strings.TrimPrefix(comment, "<#"), "#")
+	text = strings.TrimSuffix(text, "#>")
+	if i := strings.Index(text, "--"); i >= 0 {
+		text = text[:i]
+	}
+
+	fields := strings.FieldsFunc(text, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], suppressionPrefix) {
+		return "", nil, false
+	}
+	directive := strings.TrimPrefix(fields[0], suppressionPrefix)
+	switch directive {
+	case directiveDisableNextLine, directiveDisableLine, directiveDisable, directiveEnable, directiveDisableFile:
+		return directive, fields[1:], true
+	}
+	return "", nil, false
+}
+
+// closeSuppressions ends the open disable blocks for rules at the enable comment, or every
+// open block when the enable lists no rules, and returns the blocks still open
+func closeSuppressions(open []*Suppression, rules []string, at Extent) []*Suppression {
+	var stillOpen []*Suppression
+	for _, suppression := range open {
+		if len(rules) == 0 || sameRules(suppression.Rules, rules) {
+			suppression.Scope.EndLine = at.Line
+			suppression.Scope.EndColumn = at.Column
+			continue
+		}
+		stillOpen = append(stillOpen, suppression)
+	}
+	return stillOpen
+}
+
+// lineExtent covers whole lines from start to end
+func lineExtent(start, end int) Extent {
+	return Extent{Line: start, Column: 1, EndLine: end, EndColumn: math.MaxInt32}
+}
+
+// sameRules reports whether two lists name the same rules in any order
+func sameRules(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, rule := range a {
+		if !containsRule(b, rule) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsRule reports whether rules names rule, ignoring case
+func containsRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if strings.EqualFold(r, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBuiltinRule reports whether id is the ID of a built-in rule
+func isBuiltinRule(id string) bool {
+	for _, rule := range builtinRules {
+		if strings.EqualFold(rule.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// covers reports whether the suppression silences a finding
+func (s *Suppression) covers(finding Finding) bool {
+	if len(s.Rules) > 0 && !containsRule(s.Rules, finding.RuleID) {
+		return false
+	}
+	if s.Scope == (Extent{}) {
+		return true
+	}
+	if finding.Line == 0 {
+		return false
+	}
+	return s.Scope.contains(finding.Line, finding.Column)
+}
+
+// checked reports whether every rule the suppression names was checked, so that an unused
+// suppression is really stale: PSScriptAnalyzer rules only run when the analyzer is enabled
+func (s *Suppression) checked() bool {
+	for _, rule := range s.Rules {
+		if !isBuiltinRule(rule) && !analyzerEnabled {
+			return false
+		}
+	}
+	return true
+}
+
+// suppress returns the findings no suppression covers and records the others as suppressed.
+// With --ignore-suppressions every finding is returned.
+func (r *FileResult) suppress(findings []Finding) []Finding {
+	if validateIgnoreSuppressions {
+		return findings
+	}
+	kept := []Finding{}
+	for _, finding := range findings {
+		suppressed := false
+		for _, suppression := range r.suppressions {
+			if suppression.covers(finding) {
+				suppression.used = true
+				suppressed = true
+			}
+		}
+		if suppressed {
+			r.Suppressed = append(r.Suppressed, finding)
+		} else {
+			kept = append(kept, finding)
+		}
+	}
+	return kept
+}
+
+// unusedSuppressions reports the suppressions that silenced nothing. It must run after every
+// check has passed its findings through suppress.
+func (r *FileResult) unusedSuppressions() []Finding {
+	findings := []Finding{}
+	if validateIgnoreSuppressions {
+		return findings
+	}
+	for _, suppression := range r.suppressions {
+		if suppression.used || !suppression.checked() {
+			continue
+		}
+		rules := T("validate.all_rules")
+		if len(suppression.Rules) > 0 {
+			rules = strings.Join(suppression.Rules, ", ")
+		}
+		findings = append(findings, Finding{
+			RuleID: "SP001", Severity: severityWarning, Category: categoryBestPractices, File: r.File,
+			Line: suppression.Source.Line, Column: suppression.Source.Column,
+			EndLine: suppression.Source.EndLine, EndColumn: suppression.Source.EndColumn,
+			Message: T("validate.unused_suppression", rules), Source: sourceBuiltin,
+		})
+	}
+	return findings
+}
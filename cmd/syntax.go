@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SyntaxDiagnostic is a parse error reported by the PowerShell language parser
+type SyntaxDiagnostic struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Text      string `json:"text"`
+	ErrorID   string `json:"errorId"`
+	Message   string `json:"message"`
+}
+
+// Extent is a span of source text as reported by the PowerShell parser, with 1-based lines
+// and columns and the end column just past the last character
+type Extent struct {
+	Line      int `json:"line"`
+	Column    int `json:"column"`
+	EndLine   int `json:"endLine"`
+	EndColumn int `json:"endColumn"`
+}
+
+// contains reports whether a line and column lie within the extent
+func (e Extent) contains(line, column int) bool {
+	afterStart := line > e.Line || line == e.Line && column >= e.Column
+	beforeEnd := line < e.EndLine || line == e.EndLine && column < e.EndColumn
+	return afterStart && beforeEnd
+}
+
+// SyntaxTree is what the rules see of a parsed file: its tokens, the commands it invokes,
+// the functions it defines, its [SuppressMessage()] attributes, the extents of all its
+// attributes, the scripts it dot-sources and the syntax that needs a newer PowerShell.
+// RequiredVersion is the version of its #Requires -Version statement, if any. Manifest holds
+// the keys of a .psd1 data file and is nil for every other file.
+type SyntaxTree struct {
+	File            string                `json:"-"`
+	Diagnostics     []SyntaxDiagnostic    `json:"diagnostics"`
+	Tokens          []Token               `json:"tokens"`
+	Commands        []CommandNode         `json:"commands"`
+	Functions       []FunctionNode        `json:"functions"`
+	Suppressions    []SuppressMessageNode `json:"suppressions"`
+	Attributes      []Extent              `json:"attributes"`
+	DotSources      []DotSourceNode       `json:"dotSources"`
+	Features        []FeatureNode         `json:"features"`
+	RequiredVersion string                `json:"requiredVersion"`
+	Manifest        []ManifestEntry       `json:"manifest"`
+}
+
+// Token is a token of the PowerShell token stream. Kind is the parser's TokenKind, such as
+// Comment, StringLiteral or Generic; Value is the text of string tokens without quotes.
+type Token struct {
+	Extent
+	Kind  string `json:"kind"`
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// CommandNode is a command invocation, located at the command name. Parameters are the
+// parameter names given, without the dash and as typed, so they may be abbreviated.
+type CommandNode struct {
+	Extent
+	Name       string   `json:"name"`
+	Parameters []string `json:"parameters"`
+}
+
+// unqualifiedName returns the command name without a module qualifier such as
+// Microsoft.PowerShell.Utility\
+func (c CommandNode) unqualifiedName() string {
+	return c.Name[strings.LastIndex(c.Name, "\\")+1:]
+}
+
+// FunctionNode is a function definition, located at the function name. Body is the extent
+// of the function's script block from its opening brace; ParamBlock is the zero Extent when
+// the function has no param() block. HasParameters is set for parameters declared after the
+// name, as in function Get-Thing($Name); UsesArgs when the body reads $args.
+type FunctionNode struct {
+	Extent
+	Name             string `json:"name"`
+	HasParamBlock    bool   `json:"hasParamBlock"`
+	HasCmdletBinding bool   `json:"hasCmdletBinding"`
+	HasParameters    bool   `json:"hasParameters"`
+	IsFilter         bool   `json:"isFilter"`
+	UsesArgs         bool   `json:"usesArgs"`
+	Nested           bool   `json:"nested"`
+	Body             Extent `json:"body"`
+	ParamBlock       Extent `json:"paramBlock"`
+}
+
+// FeatureNode is syntax added after Windows PowerShell 5.1, such as the ternary operator.
+// Kind names the syntax as listed in languageFeatures.
+type FeatureNode struct {
+	Extent
+	Kind string `json:"kind"`
+}
+
+// DotSourceNode is a dot-sourced script, located at its path. Path is relative to the
+// directory of the dot-sourcing script, with $PSScriptRoot dropped, or "" when the path is
+// only known at run time.
+type DotSourceNode struct {
+	Extent
+	Path string `json:"path"`
+}
+
+// SuppressMessageNode is a SuppressMessage attribute on a param() block, such as
+// [SuppressMessage('BP001', 'justification')]. Scope is the extent of the function the
+// param() block belongs to, or of its script block outside a function.
+type SuppressMessageNode struct {
+	Extent
+	Rule  string `json:"rule"`
+	Scope Extent `json:"scope"`
+}
+
+// ManifestEntry is a key of a .psd1 data file, located at the key. Keys of nested hashtables
+// are named by their path, such as PrivateData.PSData.Prerelease. Values holds the value, or
+// each item of an array, as text; Constant is false when the value is computed and unknown.
+type ManifestEntry struct {
+	Extent
+	Key      string   `json:"key"`
+	Values   []string `json:"values"`
+	Constant bool     `json:"constant"`
+}
+
+// parsePowerShellFiles parses each file with System.Management.Automation.Language.Parser
+// and returns the parse errors and syntax tree of every file, keyed by the given file name
+func parsePowerShellFiles(files []string) (map[string]*SyntaxTree, error) {
+	return parsePowerShellFilesIn(sharedPowerShell, files)
+}
+
+// parsePowerShellFilesIn parses the files with the PowerShell workers of pool
+func parsePowerShellFilesIn(pool *powerShellPool, files []string) (map[string]*SyntaxTree, error) {
+	paths, err := scriptPathArgs(files)
+	if err != nil {
+		return nil, err
+	}
+	output, err := pool.run("parse.ps1", scriptParams{"Path": paths})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Path string `json:"path"`
+		SyntaxTree
+	}
+	if err := json.Unmarshal(output, &results); err != nil {
+		return nil, fmt.Errorf("unexpected parser output: %s", strings.TrimSpace(string(output)))
+	}
+
+	names := map[string]string{}
+	for i, path := range paths {
+		names[path] = files[i]
+	}
+	trees := map[string]*SyntaxTree{}
+	for i := range results {
+		tree := &results[i].SyntaxTree
+		name, ok := names[results[i].Path]
+		if !ok {
+			return nil, fmt.Errorf("unexpected parser output for %s", results[i].Path)
+		}
+		tree.File = name
+		for j := range tree.Diagnostics {
+			tree.Diagnostics[j].File = name
+		}
+		trees[name] = tree
+	}
+	return trees, nil
+}
+
+// parsePowerShellFile parses a single file and returns its parse errors and syntax tree
+func parsePowerShellFile(filename string) (*SyntaxTree, error) {
+	trees, err := parsePowerShellFiles([]string{filename})
+	if err != nil {
+		return nil, err
+	}
+	tree, ok := trees[filename]
+	if !ok {
+		return nil, fmt.Errorf("no parser output for %s", filename)
+	}
+	return tree, nil
+}
+
+// syntaxFinding converts a parse error into a finding
+func syntaxFinding(diagnostic SyntaxDiagnostic) Finding {
+	message := diagnostic.Message
+	if diagnostic.ErrorID != "" {
+		message = fmt.Sprintf("%s (%s)", message, diagnostic.ErrorID)
+	}
+	return Finding{
+		RuleID:    "SY001",
+		Severity:  severityError,
+		Category:  categorySyntax,
+		File:      diagnostic.File,
+		Line:      diagnostic.Line,
+		Column:    diagnostic.Column,
+		EndLine:   diagnostic.EndLine,
+		EndColumn: diagnostic.EndColumn,
+		Message:   message,
+		Source:    sourceBuiltin,
+	}
+}
+
+// printSyntaxDiagnostic prints a diagnostic with its location and a source snippet
+// with a caret under the offending text, e.g.
+//
+//	❌ Syntax Error: Missing closing '}' in statement block or type definition. (MissingEndCurlyBrace)
+//	   --> script.ps1:3:14
+//	    |
+//	  3 | function Foo {
+//	    |              ^
+func printSyntaxDiagnostic(out io.Writer, diagnostic SyntaxDiagnostic, source []string) {
+	message := diagnostic.Message
+	if diagnostic.ErrorID != "" {
+		message = fmt.Sprintf("%s (%s)", message, diagnostic.ErrorID)
+	}
+	fmt.Fprintln(out, "  "+T("validate.syntax_error", message))
+	fmt.Fprintf(out, "     --> %s:%d:%d\n", diagnostic.File, diagnostic.Line, diagnostic.Column)
+
+	for _, line := range sourceSnippet(diagnostic, source) {
+		fmt.Fprintf(out, "     %s\n", line)
+	}
+}
+
+// sourceSnippet renders the diagnostic's source line with a gutter and a caret line
+func sourceSnippet(diagnostic SyntaxDiagnostic, source []string) []string {
+	if diagnostic.Line < 1 || diagnostic.Line > len(source) {
+		return nil
+	}
+
+	line := strings.TrimRight(source[diagnostic.Line-1], "\r")
+	number := fmt.Sprintf("%d", diagnostic.Line)
+	gutter := strings.Repeat(" ", len(number))
+
+	return []string{
+		gutter + " |",
+		number + " | " + line,
+		gutter + " | " + caretLine(line, diagnostic),
+	}
+}
+
+// caretLine builds the marker under the extent, keeping tabs so it lines up with the source
+func caretLine(line string, diagnostic SyntaxDiagnostic) string {
+	runes := []rune(line)
+	start := diagnostic.Column - 1
+	if start < 0 {
+		start = 0
+	}
+	if start > len(runes) {
+		start = len(runes)
+	}
+
+	width := 1
+	if diagnostic.EndLine == diagnostic.Line && diagnostic.EndColumn > diagnostic.Column {
+		width = diagnostic.EndColumn - diagnostic.Column
+	} else if diagnostic.EndLine > diagnostic.Line && len(runes) > start {
+		width = len(runes) - start
+	}
+
+	var marker strings.Builder
+	for _, r := range runes[:start] {
+		if r == '\t' {
+			marker.WriteRune('\t')
+		} else {
+			marker.WriteRune(' ')
+		}
+	}
+	marker.WriteString(strings.Repeat("^", width))
+	return marker.String()
+}
+
+// readSourceLines returns the lines of filename, or nil if it cannot be read
+func readSourceLines(filename string) []string {
+	content, err := os.ReadFile(sourceFile(filename))
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(content), "\n")
+}
@@ -1,239 +1,681 @@
 package cmd
 
 import (
-"fmt"
-"os"
-"os/exec"
-"path/filepath"
-"strings"
-
-"github.com/spf13/cobra"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
 )
 
-var validateCmd = &cobra.Command{
-Use:   "validate",
-Short: "Validate your PowerShell solution locally",
-Long:  `Test your PowerShell code locally before committing to ensure it works correctly`,
-Run: func(cmd *cobra.Command, args []string) {
-runValidation()
-},
-}
-
-func runValidation() {
-fmt.Println("🧪 PowerShell Solution Validation")
-fmt.Println("=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=" + "=")
-
-// Check if PowerShell is available
-if !isPowerShellAvailable() {
-fmt.Println("❌ PowerShell not found. Please install PowerShell 7+ for cross-platform compatibility.")
-fmt.Println("   Visit: https://github.com/PowerShell/PowerShell#get-powershell")
-return
-}
-
-fmt.Println("✅ PowerShell detected")
-
-// Find PowerShell files to validate
-psFiles := findPowerShellFiles()
-if len(psFiles) == 0 {
-fmt.Println("❌ No PowerShell files found in current directory")
-fmt.Println("   Make sure you have created .ps1 files for your solution")
-return
-}
-
-fmt.Printf("🔍 Found %d PowerShell file(s) to validate:\n", len(psFiles))
-for _, file := range psFiles {
-fmt.Printf("   • %s\n", file)
-}
-fmt.Println()
+var (
+	validateJobs       int
+	validateSettings   string
+	validateNoAnalyzer bool
+	validateTests      bool
+	validateFormat     string
+	validateOutput     string
+	validateFailOn     string
+	validateFix        bool
+	validateDryRun     bool
+	validateChanged    string
+	validateStaged     bool
+	validateCommits    string
+	validateWatch      bool
+	validatePowerShell []string
+	validateListFiles  bool
+
+	validateIgnoreSuppressions bool
+)
 
-// Validate each file
-allValid := true
-for _, file := range psFiles {
-if !validatePowerShellFile(file) {
-allValid = false
-}
-}
+// analyzerEnabled and analyzerSettings are set by detectScriptAnalyzer before files are
+// validated and only read while validation runs
+var (
+	analyzerEnabled  bool
+	analyzerSettings string
+)
 
-fmt.Println()
-if allValid {
-fmt.Println("🎉 All validations passed!")
-fmt.Println("🚀 Your solution is ready to commit and push!")
-fmt.Println()
-fmt.Println("Next steps:")
-fmt.Println("1. git add .")
-fmt.Println("2. git commit -m \"Complete step X\"")
-fmt.Println("3. git push")
-fmt.Println()
-fmt.Println("💡 Use ''gh pwsh-skills status'' to check your progress")
-} else {
-fmt.Println("❌ Some validations failed. Please fix the issues and try again.")
-}
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate your PowerShell solution locally",
+	Long: `Test your PowerShell code locally before committing to ensure it works correctly.
+
+Files are checked by PowerShell processes that are started once and reused, so
+validating many files does not cost one PowerShell startup per file. Up to --jobs
+files are checked in parallel; results are still printed file by file in the
+order the files were found.
+
+When the PSScriptAnalyzer module is installed, Invoke-ScriptAnalyzer runs on each
+file and its findings are merged with the built-in best practice checks. Analyzer
+errors fail the file; warnings and information are reported. Use --settings to
+choose a settings file or a preset such as PSGallery.
+
+Each file reports the oldest PowerShell it runs on and the syntax or cmdlet that
+needs it, such as the ternary operator or ForEach-Object -Parallel, checked against
+its #Requires -Version.
+
+Files fail on error findings. Use --fail-on warning to fail them on warnings too,
+such as Windows-only cmdlets or PSScriptAnalyzer warnings.
+
+Scripts (.ps1), script modules (.psm1), module manifests and other data files (.psd1)
+and formatting and type files (.ps1xml) are validated. Module manifests are checked
+for required keys, valid versions, files that exist and a FunctionsToExport that
+matches the functions the module defines, and every module is imported in a fresh
+PowerShell session to make sure it loads cleanly.
+
+Findings can be suppressed in the script with comments such as
+  # pwsh-skills-disable-next-line CP001 -- this exercise manages Windows services
+or with [Diagnostics.CodeAnalysis.SuppressMessage('CP001', '')] on a param() block.
+Suppressions that silence nothing are reported as SP001; --ignore-suppressions
+reports every finding regardless, to audit what is being suppressed.
+
+With --fix, files are rewritten before they are validated to apply the safe fixes:
+[CmdletBinding()] and an empty param() block for functions, after their comment-based
+help, full cmdlet names for aliases, Join-Path expressions for hardcoded paths under the
+user's home or temporary folder, consistent line endings, and a byte order mark for the
+UTF-8 files PSUseBOMForUnicodeEncodedFile reports. Files otherwise keep their encoding. Add --dry-run to print the
+changes as a unified diff instead. Suppressed findings are never fixed.
+
+Use --changed to validate only the files git reports as added, modified or renamed
+since the last commit, uncommitted changes included, and untracked files; give a ref
+as --changed=main to validate everything changed on a branch. --staged validates what
+is about to be committed: the staged content of the staged files, not the working
+tree version. Modules are checked and imported with the staged content of the files
+they load. --commits validates what a push sends: the committed content of the files a
+range of commits changes, as in --commits=origin/main..HEAD, or of every file for a
+single commit.
+
+Use --powershell, or the powershell setting of .pwsh-skills.yml, to choose the
+PowerShell executables to validate with, such as --powershell pwsh,pwsh-preview. The
+first runs every check. With more than one, the syntax and import checks also run
+with the others, and a matrix shows how each file fares with each version.
+
+Every PowerShell file below the current directory is validated, except in hidden
+directories and in node_modules, bin and obj directories. Files matched by .gitignore,
+.git/info/exclude or a .pwsh-skillsignore file, which has the .gitignore syntax, are
+skipped, as are the exclude globs of .pwsh-skills.yml. Symlinks are followed only to
+files and directories inside the repository. Use --list-files to see which files
+would be validated and why the others are skipped.
+
+With --watch, every file is validated and the directory is then watched: the files
+affected by each change, including the module manifests next to a changed script,
+are validated again and a compact summary is redrawn. Editing .pwsh-skills.yml, a
+.gitignore or a .pwsh-skillsignore inside the directory reloads it and validates every
+file again; changes above the directory or to .git/info/exclude need a restart. Watch
+validates with one PowerShell executable. Press Ctrl+C to stop.
+
+With --tests, the Pester tests (*.Tests.ps1) are run after validation, as with
+the test command, and failing tests fail the command. --test-timeout bounds the
+whole test run, as --timeout does for the test command.
+
+Use --format to produce a report instead of the text output, and --output to
+write it to a file:
+  json    the native format, described by docs/validate-report.schema.json
+  junit   JUnit XML with one test suite per file and one test case per check
+  sarif   SARIF 2.1.0 for GitHub code scanning or VS Code's SARIF viewer
+
+The command exits with 1 when a file or test fails, 2 for invalid flags and 3 when
+PowerShell or a module it needs is missing, so it can gate commits and CI.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := useProjectConfig(); err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("format") && projectConfig.Format != "" {
+			validateFormat = projectConfig.Format
+		}
+		if !cmd.Flags().Changed("fail-on") && projectConfig.FailOn != "" {
+			validateFailOn = projectConfig.FailOn
+		}
+		return runValidation()
+	},
+}
+
+// failOnSeverities are the accepted values of --fail-on
+var failOnSeverities = []string{severityError, severityWarning}
+
+// runValidation validates the files and writes the report. Errors already explained by the
+// text output are returned as reported.
+func runValidation() error {
+	if !validFormat(validateFormat) {
+		return exitError(exitUsage, fmt.Errorf("unknown format %q, expected one of: %s", validateFormat, strings.Join(validateFormats, ", ")))
+	}
+	if validateFailOn != severityError && validateFailOn != severityWarning {
+		return exitError(exitUsage, fmt.Errorf("unknown --fail-on severity %q, expected one of: %s", validateFailOn, strings.Join(failOnSeverities, ", ")))
+	}
+	if validateDryRun && !validateFix {
+		return exitError(exitUsage, errors.New("--dry-run only applies to --fix"))
+	}
+	selections := 0
+	for _, set := range []bool{validateStaged, validateChanged != "", validateCommits != ""} {
+		if set {
+			selections++
+		}
+	}
+	if selections > 1 {
+		return exitError(exitUsage, errors.New("--changed, --staged and --commits cannot be combined"))
+	}
+	if (validateStaged || validateCommits != "") && validateFix {
+		return exitError(exitUsage, errors.New("--fix changes the working tree and cannot be combined with --staged or --commits"))
+	}
+	if validateListFiles {
+		if selections > 0 || validateWatch {
+			return exitError(exitUsage, errors.New("--list-files cannot be combined with --changed, --staged, --commits or --watch"))
+		}
+		discovery := newFileDiscovery()
+		discovery.scan(".")
+		printFileDecisions(os.Stdout, discovery.decisions)
+		return nil
+	}
+	if err := configurePowerShellExecutables(); err != nil {
+		return err
+	}
+	if validateWatch {
+		return watchValidation(os.Stdout)
+	}
+
+	// Text goes to stdout unless a report format takes its place there
+	out := io.Writer(os.Stdout)
+	if validateFormat != formatText && validateOutput == "" {
+		out = io.Discard
+	}
+
+	fmt.Fprintln(out, T("validate.title"))
+	fmt.Fprintln(out, "="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"="+"=")
+
+	// Check if PowerShell is available
+	if !isPowerShellAvailable() {
+		fmt.Fprintln(out, T("validate.pwsh_missing"))
+		fmt.Fprintln(out, "   "+T("validate.pwsh_install"))
+		return reportedIf(out, errPowerShellNotFound)
+	}
+
+	fmt.Fprintln(out, T("validate.pwsh_detected"))
+	if projectConfig.path != "" {
+		fmt.Fprintln(out, T("validate.using_config", projectConfig.path))
+	}
+
+	// Find PowerShell files to validate, or only the changed ones
+	psFiles, cleanup, err := selectValidationFiles()
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	if len(psFiles) == 0 {
+		switch {
+		case validateStaged:
+			fmt.Fprintln(out, T("validate.no_staged_files"))
+		case validateCommits != "":
+			fmt.Fprintln(out, T("validate.no_committed_files", validateCommits))
+		case validateChanged != "":
+			fmt.Fprintln(out, T("validate.no_changed_files", validateChanged))
+		default:
+			fmt.Fprintln(out, T("validate.no_files"))
+			fmt.Fprintln(out, "   "+T("validate.no_files_tip"))
+		}
+		return writeValidationReport(ValidationReport{})
+	}
+
+	if !detectScriptAnalyzer(out) {
+		return reportedIf(out, exitError(exitUsage, fmt.Errorf("PSScriptAnalyzer settings not found: %s", validateSettings)))
+	}
+
+	switch {
+	case validateStaged:
+		fmt.Fprintln(out, T("validate.staged_files", len(psFiles)))
+	case validateCommits != "":
+		fmt.Fprintln(out, T("validate.committed_files", len(psFiles), validateCommits))
+	case validateChanged != "":
+		fmt.Fprintln(out, T("validate.changed_files", len(psFiles), validateChanged))
+	default:
+		fmt.Fprintln(out, T("validate.found_files", len(psFiles)))
+	}
+	for _, file := range psFiles {
+		fmt.Fprintf(out, "   • %s\n", file)
+	}
+	fmt.Fprintln(out)
+
+	if validateFix {
+		fixFiles(out, psFiles, validateDryRun)
+	}
+
+	// Validate the files concurrently, printing each file's results in order
+	results := validateFiles(out, psFiles, validateJobs)
+	failed := 0
+	for _, result := range results {
+		if !result.Valid {
+			failed++
+		}
+	}
+
+	// Run the syntax and import checks with every configured PowerShell executable
+	var matrix *CompatibilityMatrix
+	matrixFailed := 0
+	if len(powerShellExecutables) > 1 {
+		fmt.Fprintln(out)
+		matrix = runCompatibilityMatrix(psFiles)
+		printCompatibilityMatrix(out, matrix, psFiles)
+		matrixFailed = len(matrix.failedFiles())
+	}
+
+	fmt.Fprintln(out)
+	if failed == 0 && matrixFailed == 0 {
+		fmt.Fprintln(out, T("validate.all_passed"))
+		fmt.Fprintln(out, T("validate.ready"))
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, T("common.next_steps"))
+		fmt.Fprintln(out, "1. git add .")
+		fmt.Fprintln(out, "2. git commit -m \""+T("validate.commit_message")+"\"")
+		fmt.Fprintln(out, "3. git push")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, T("validate.status_tip"))
+	} else {
+		fmt.Fprintln(out, T("validate.some_failed"))
+	}
+
+	report := ValidationReport{Files: results, Matrix: matrix}
+	var testsErr error
+	if validateTests {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, T("test.title"))
+		fmt.Fprintln(out, "=============================================")
+		report.TestsRun = true
+		report.Tests, testsErr = runTests(out)
+		if testsErr != nil && report.Tests == nil {
+			report.TestsError = testsErr.Error()
+		}
+	}
+
+	if err := writeValidationReport(report); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return reportedIf(out, exitError(exitFailure, fmt.Errorf("%d of %d files failed validation", failed, len(results))))
+	}
+	if matrixFailed > 0 {
+		return reportedIf(out, exitError(exitFailure, fmt.Errorf("%d of %d files fail with some PowerShell executables", matrixFailed, len(results))))
+	}
+	return reportedIf(out, testsErr)
+}
+
+// configurePowerShellExecutables resolves the executables given with --powershell, or else
+// in the powershell setting of the project configuration
+func configurePowerShellExecutables() error {
+	names, dir := validatePowerShell, "."
+	if len(names) == 0 && len(projectConfig.PowerShell) > 0 {
+		names, dir = projectConfig.PowerShell, filepath.Dir(projectConfig.path)
+	}
+	executables, err := resolvePowerShellExecutables(names, dir)
+	if err != nil {
+		return err
+	}
+	powerShellExecutables = executables
+	return nil
+}
+
+// reportedIf marks err as reported when the text output explaining it went to the terminal
+// or a file rather than being discarded in favour of a report on stdout
+func reportedIf(out io.Writer, err error) error {
+	if out == io.Discard {
+		return err
+	}
+	return reported(err)
+}
+
+// writeValidationReport writes the results in the --format report format to --output or
+// stdout. Nothing is written for the text format, which is printed while validating.
+func writeValidationReport(report ValidationReport) error {
+	if validateFormat == formatText {
+		return nil
+	}
+
+	w := io.Writer(os.Stdout)
+	if validateOutput != "" {
+		file, err := os.Create(validateOutput)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch validateFormat {
+	case formatJSON:
+		return writeJSON(w, report)
+	case formatJUnit:
+		return writeJUnit(w, report)
+	case formatSARIF:
+		return writeSARIF(w, report.Files)
+	}
+	return nil
+}
+
+// validateFiles validates files on up to jobs goroutines, each with its own PowerShell worker.
+// Every file's output is buffered and written in the original order once all files before it
+// have finished, so output never interleaves and the result does not depend on scheduling.
+func validateFiles(out io.Writer, files []string, jobs int) []FileResult {
+	if jobs > len(files) {
+		jobs = len(files)
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+	setPowerShellWorkers(jobs)
+
+	type pending struct {
+		output bytes.Buffer
+		result FileResult
+		done   chan struct{}
+	}
+	pendings := make([]*pending, len(files))
+	for i := range pendings {
+		pendings[i] = &pending{done: make(chan struct{})}
+	}
+
+	indexes := make(chan int)
+	go func() {
+		for i := range files {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+	for j := 0; j < jobs; j++ {
+		go func() {
+			for i := range indexes {
+				pendings[i].result = validatePowerShellFile(&pendings[i].output, files[i])
+				close(pendings[i].done)
+			}
+		}()
+	}
+
+	results := make([]FileResult, len(files))
+	for i, p := range pendings {
+		<-p.done
+		out.Write(p.output.Bytes())
+		results[i] = p.result
+	}
+	return results
+}
+
+// detectScriptAnalyzer decides whether PSScriptAnalyzer runs for this validation and which
+// settings it uses. It returns false when the requested settings cannot be used.
+func detectScriptAnalyzer(out io.Writer) bool {
+	analyzerEnabled = false
+	if validateNoAnalyzer {
+		return true
+	}
+
+	settings, err := resolveAnalyzerSettings(validateSettings)
+	if err != nil {
+		fmt.Fprintln(out, T("validate.analyzer_settings_missing", validateSettings))
+		return false
+	}
+
+	version, err := scriptAnalyzerVersion()
+	if err != nil || version == "" {
+		fmt.Fprintln(out, T("validate.analyzer_missing"))
+		fmt.Fprintln(out, "   "+T("validate.analyzer_install"))
+		return true
+	}
+
+	analyzerEnabled = true
+	analyzerSettings = settings
+	if settings != "" {
+		fmt.Fprintln(out, T("validate.analyzer_detected_settings", version, settings))
+	} else {
+		fmt.Fprintln(out, T("validate.analyzer_detected", version))
+	}
+	return true
 }
 
 func isPowerShellAvailable() bool {
-// Check for pwsh (PowerShell 7+)
-_, err := exec.LookPath("pwsh")
-if err == nil {
-return true
-}
-
-// Check for powershell (Windows PowerShell)
-_, err = exec.LookPath("powershell")
-return err == nil
+	// Check for pwsh (PowerShell 7+) or powershell (Windows PowerShell)
+	_, err := powerShellExecutable()
+	return err == nil
 }
 
 func findPowerShellFiles() []string {
-var files []string
-
-// Look for .ps1 files in current directory
-filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-if err != nil {
-return nil
-}
-
-// Skip hidden directories and files
-if strings.HasPrefix(info.Name(), ".") {
-if info.IsDir() {
-return filepath.SkipDir
-}
-return nil
-}
-
-// Skip certain directories
-skipDirs := []string{"node_modules", "bin", "obj", ".git"}
-for _, skipDir := range skipDirs {
-if strings.Contains(path, skipDir) {
-return nil
-}
-}
-
-if strings.HasSuffix(strings.ToLower(info.Name()), ".ps1") {
-files = append(files, path)
-}
-
-return nil
-})
-
-return files
-}
-
-func validatePowerShellFile(filename string) bool {
-fmt.Printf("🔍 Validating: %s\n", filename)
-
-// 1. Syntax validation
-if !validateSyntax(filename) {
-return false
-}
-
-// 2. Cross-platform compatibility check
-if !checkCrossPlatformCompatibility(filename) {
-return false
-}
-
-// 3. Best practices check
-checkBestPractices(filename)
-
-fmt.Printf("✅ %s - All checks passed\n", filename)
-return true
-}
-
-func validateSyntax(filename string) bool {
-// Use PowerShell to parse the script and check for syntax errors
-var cmd *exec.Cmd
-
-// Try pwsh first (PowerShell 7+)
-if _, err := exec.LookPath("pwsh"); err == nil {
-cmd = exec.Command("pwsh", "-NoProfile", "-Command", 
-fmt.Sprintf("try { $null = [System.Management.Automation.PSParser]::Tokenize((Get-Content ''%s'' -Raw), [ref]$null); Write-Host ''OK'' } catch { Write-Error $_.Exception.Message; exit 1 }", filename))
-} else {
-cmd = exec.Command("powershell", "-NoProfile", "-Command", 
-fmt.Sprintf("try { $null = [System.Management.Automation.PSParser]::Tokenize((Get-Content ''%s'' -Raw), [ref]$null); Write-Host ''OK'' } catch { Write-Error $_.Exception.Message; exit 1 }", filename))
-}
-
-output, err := cmd.CombinedOutput()
-if err != nil {
-fmt.Printf("  ❌ Syntax Error: %s\n", strings.TrimSpace(string(output)))
-return false
-}
-
-fmt.Printf("  ✅ Syntax: Valid\n")
-return true
-}
-
-func checkCrossPlatformCompatibility(filename string) bool {
-// Read file content
-content, err := os.ReadFile(filename)
-if err != nil {
-fmt.Printf("  ❌ Could not read file: %v\n", err)
-return false
-}
-
-fileContent := string(content)
-issues := []string{}
-
-// Check for Windows-specific cmdlets that might not work on Linux/macOS
-windowsOnlyCmdlets := []string{
-"Get-WmiObject",
-"Get-Service", // Note: Available on Linux but with limited functionality
-"New-Service",
-"Set-Service",
-"Get-EventLog",
-"Get-WindowsFeature",
-}
-
-for _, cmdlet := range windowsOnlyCmdlets {
-if strings.Contains(fileContent, cmdlet) {
-issues = append(issues, fmt.Sprintf("''%s'' may not work on all platforms", cmdlet))
-}
-}
-
-// Check for hardcoded Windows paths
-if strings.Contains(fileContent, "C:\\") || strings.Contains(fileContent, "\\\\") {
-issues = append(issues, "Hardcoded Windows paths detected")
-}
-
-if len(issues) > 0 {
-fmt.Printf("  ⚠️  Cross-platform compatibility warnings:\n")
-for _, issue := range issues {
-fmt.Printf("     • %s\n", issue)
-}
-} else {
-fmt.Printf("  ✅ Cross-platform: Compatible\n")
-}
-
-return true // Don''t fail on warnings, just inform
-}
-
-func checkBestPractices(filename string) {
-content, err := os.ReadFile(filename)
-if err != nil {
-return
-}
-
-fileContent := string(content)
-suggestions := []string{}
-
-// Check for common best practices
-if !strings.Contains(fileContent, "[CmdletBinding()]") && strings.Contains(fileContent, "function") {
-suggestions = append(suggestions, "Consider adding [CmdletBinding()] to functions")
-}
-
-if strings.Contains(fileContent, "Write-Host") {
-suggestions = append(suggestions, "Consider using Write-Output instead of Write-Host for better pipeline support")
-}
-
-if !strings.Contains(fileContent, "param(") && strings.Contains(fileContent, "function") {
-suggestions = append(suggestions, "Consider adding parameter blocks to functions")
-}
+	files, _ := scanPowerShellTree(".")
+	return files
+}
+
+// scanPowerShellTree returns the PowerShell files below root and the directories searched
+// for them, as decided by file discovery
+func scanPowerShellTree(root string) (files, dirs []string) {
+	return newFileDiscovery().scan(root)
+}
+
+// FileResult is the outcome of validating one file: how each check went, what it found and
+// what suppressions silenced. The minimum PowerShell version, syntax tree and suppressions
+// are set once the file parses.
+type FileResult struct {
+	File           string          `json:"file"`
+	Valid          bool            `json:"valid"`
+	Checks         []CheckResult   `json:"checks"`
+	Findings       []Finding       `json:"findings"`
+	Suppressed     []Finding       `json:"suppressed,omitempty"`
+	MinimumVersion *MinimumVersion `json:"minimumVersion,omitempty"`
+	tree           *SyntaxTree
+	suppressions   []*Suppression
+}
+
+// CheckResult is the outcome of one check on a file. Problem explains a check that could
+// not run properly, such as PowerShell failing to parse the file.
+type CheckResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Problem string `json:"problem,omitempty"`
+}
+
+// Statuses of a CheckResult
+const (
+	checkPassed  = "passed"
+	checkWarning = "warning"
+	checkFailed  = "failed"
+	checkError   = "error"
+	checkSkipped = "skipped"
+)
 
-if len(suggestions) > 0 {
-fmt.Printf("  💡 Best practice suggestions:\n")
-for _, suggestion := range suggestions {
-fmt.Printf("     • %s\n", suggestion)
-}
-}
+// addCheck records the outcome of a check
+func (r *FileResult) addCheck(name, status string, problem error) {
+	check := CheckResult{Name: name, Status: status}
+	if problem != nil {
+		check.Problem = problem.Error()
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// skipRemainingChecks records the checks that did not run because an earlier one failed
+func (r *FileResult) skipRemainingChecks() {
+	for _, name := range fileChecks(r.File)[len(r.Checks):] {
+		r.addCheck(name, checkSkipped, nil)
+	}
+}
+
+// checkStatus derives a check's status from the severity of its findings: failed when one
+// of them fails the file under --fail-on, otherwise warning when there are any
+func checkStatus(findings []Finding) string {
+	status := checkPassed
+	for _, finding := range findings {
+		if failsValidation(finding) {
+			return checkFailed
+		}
+		status = checkWarning
+	}
+	return status
+}
+
+// failsValidation reports whether a finding is severe enough to fail its file under --fail-on
+func failsValidation(finding Finding) bool {
+	switch finding.Severity {
+	case severityError:
+		return true
+	case severityWarning:
+		return validateFailOn == severityWarning
+	}
+	return false
+}
+
+// passed reports whether every check on the file passed or only warned
+func (r *FileResult) passed() bool {
+	for _, check := range r.Checks {
+		if check.Status != checkPassed && check.Status != checkWarning {
+			return false
+		}
+	}
+	return true
+}
+
+func validatePowerShellFile(out io.Writer, filename string) FileResult {
+	fmt.Fprintln(out, T("validate.validating", filename))
+	result := FileResult{File: filename, Findings: []Finding{}}
+
+	// 1. Syntax validation; the other checks need a file that parses. Formatting and type
+	// files are XML, and only checked for being well-formed.
+	validate := validateSyntax
+	if strings.EqualFold(filepath.Ext(filename), ".ps1xml") {
+		validate = validateXML
+	}
+	if !validate(out, &result) {
+		result.skipRemainingChecks()
+		fmt.Fprintln(out, T("validate.file_failed", filename))
+		return result
+	}
+
+	// 2. Module manifest check and import in a fresh PowerShell session, for script modules
+	// and for the .psd1 files that are module manifests rather than other data files
+	if containsString(fileChecks(filename), categoryModule) && (strings.EqualFold(filepath.Ext(filename), ".psm1") || isModuleManifest(result.tree)) {
+		checkModule(out, &result)
+	}
+
+	if result.tree != nil {
+		// 3. Cross-platform compatibility check
+		checkCrossPlatformCompatibility(out, &result)
+
+		// 4. Best practices check, including PSScriptAnalyzer when it is installed
+		checkBestPractices(out, &result)
+	}
+
+	if len(result.Suppressed) > 0 {
+		fmt.Fprintln(out, "  "+T("validate.suppressed", len(result.Suppressed)))
+	}
+	result.Valid = result.passed()
+	if result.Valid {
+		fmt.Fprintln(out, T("validate.file_passed", filename))
+	} else {
+		fmt.Fprintln(out, T("validate.file_failed", filename))
+	}
+	return result
+}
+
+func validateSyntax(out io.Writer, result *FileResult) bool {
+	// Use the PowerShell language parser to find syntax errors with their exact location
+	tree, err := parsePowerShellFile(result.File)
+	if err != nil {
+		fmt.Fprintln(out, "  "+T("validate.syntax_error", err))
+		result.addCheck(categorySyntax, checkError, err)
+		return false
+	}
+
+	if len(tree.Diagnostics) > 0 {
+		source := readSourceLines(result.File)
+		for _, diagnostic := range tree.Diagnostics {
+			printSyntaxDiagnostic(out, diagnostic, source)
+			result.Findings = append(result.Findings, syntaxFinding(diagnostic))
+		}
+		result.addCheck(categorySyntax, checkFailed, nil)
+		return false
+	}
+
+	fmt.Fprintln(out, "  "+T("validate.syntax_valid"))
+	result.tree = tree
+	result.suppressions = findSuppressions(tree)
+	result.addCheck(categorySyntax, checkPassed, nil)
+	return true
+}
+
+// checkCrossPlatformCompatibility reports cmdlets and paths that only work on Windows, and
+// the oldest PowerShell the file runs on
+func checkCrossPlatformCompatibility(out io.Writer, result *FileResult) {
+	minimum := minimumVersion(result.tree)
+	result.MinimumVersion = &minimum
+	details := []string{}
+	if minimum.Syntax != "" {
+		details = append(details, T("validate.minimum_version_use", minimum.Syntax, minimum.Line))
+	}
+	if minimum.Declared != "" {
+		details = append(details, "#Requires -Version "+minimum.Declared)
+	}
+	if len(details) > 0 {
+		fmt.Fprintln(out, "  "+T("validate.minimum_version", minimum.Version)+" ("+strings.Join(details, "; ")+")")
+	} else {
+		fmt.Fprintln(out, "  "+T("validate.minimum_version", minimum.Version))
+	}
+
+	issues := result.suppress(projectConfig.configure(runRules(result.tree, categoryCrossPlatform)))
+	if len(issues) > 0 {
+		fmt.Fprintln(out, "  "+T("validate.cross_platform_warnings"))
+		for _, issue := range issues {
+			printFinding(out, issue)
+		}
+	} else {
+		fmt.Fprintln(out, "  "+T("validate.cross_platform_ok"))
+	}
+	result.Findings = append(result.Findings, issues...)
+	result.addCheck(categoryCrossPlatform, checkStatus(issues), nil)
+}
+
+// checkBestPractices reports built-in best practice findings merged with PSScriptAnalyzer's.
+// As the last check it also reports the file's unused suppressions.
+func checkBestPractices(out io.Writer, result *FileResult) {
+	findings := runRules(result.tree, categoryBestPractices)
+
+	var problem error
+	if analyzerEnabled {
+		analyzerFindings, err := runScriptAnalyzer(result.File, analyzerSettings)
+		if err != nil {
+			fmt.Fprintln(out, "  "+T("validate.analyzer_failed", err))
+			problem = err
+		}
+		findings = mergeFindings(findings, analyzerFindings)
+	}
+	findings = result.suppress(projectConfig.configure(findings))
+	findings = append(findings, projectConfig.configure(result.unusedSuppressions())...)
+	sortFindings(findings)
+
+	if len(findings) > 0 {
+		fmt.Fprintln(out, "  "+T("validate.best_practices"))
+		for _, finding := range findings {
+			printFinding(out, finding)
+		}
+	}
+	result.Findings = append(result.Findings, findings...)
+	result.addCheck(categoryBestPractices, checkStatus(findings), problem)
 }
 
 func init() {
-rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().IntVarP(&validateJobs, "jobs", "j", runtime.NumCPU(), "Number of files to validate in parallel")
+	validateCmd.Flags().StringVar(&validateSettings, "settings", "", "PSScriptAnalyzer settings file or preset (default: "+analyzerSettingsFile+" if present)")
+	validateCmd.Flags().BoolVar(&validateNoAnalyzer, "no-analyzer", false, "Skip PSScriptAnalyzer and run only the built-in checks")
+	validateCmd.Flags().BoolVar(&validateTests, "tests", false, "Also run the Pester tests (*.Tests.ps1) and fail when a test fails")
+	validateCmd.Flags().DurationVar(&testTimeout, "test-timeout", testTimeout, "With --tests, how long the whole test run may take")
+	validateCmd.Flags().StringVar(&validateFormat, "format", formatText, "Output format: "+strings.Join(validateFormats, ", "))
+	validateCmd.Flags().StringVarP(&validateOutput, "output", "o", "", "Write the --format report to a file and keep the text output on stdout")
+	validateCmd.Flags().BoolVar(&validateIgnoreSuppressions, "ignore-suppressions", false, "Report findings silenced by suppression comments and attributes, to audit them")
+	validateCmd.Flags().StringVar(&validateFailOn, "fail-on", severityError, "Lowest finding severity that fails a file: "+strings.Join(failOnSeverities, ", "))
+	validateCmd.Flags().BoolVar(&validateFix, "fix", false, "Rewrite files to apply safe fixes before validating them")
+	validateCmd.Flags().BoolVar(&validateDryRun, "dry-run", false, "With --fix, show the fixes as a unified diff without changing any file")
+	validateCmd.Flags().StringVar(&validateChanged, "changed", "", "Validate only the files changed since the merge base with a ref (--changed=<ref>) and untracked files")
+	validateCmd.Flags().Lookup("changed").NoOptDefVal = changedBaseDefault
+	validateCmd.Flags().BoolVar(&validateStaged, "staged", false, "Validate the staged content of the files staged for commit")
+	validateCmd.Flags().StringVar(&validateCommits, "commits", "", "Validate the committed content of the files changed in a range of commits (<from>..<to>), or of every file in one commit")
+	validateCmd.Flags().BoolVarP(&validateWatch, "watch", "w", false, "Keep validating the files affected by each change until interrupted")
+	validateCmd.Flags().StringSliceVar(&validatePowerShell, "powershell", nil, "PowerShell executables to validate with, as names or paths; with more than one, a compatibility matrix is printed")
+	validateCmd.Flags().BoolVar(&validateListFiles, "list-files", false, "List the files validate would check and why others are skipped, without validating")
+	validateCmd.Flags().DurationVar(&powerShellTimeout, "timeout", powerShellTimeout, "How long to wait for PowerShell to start or check a file")
+	rootCmd.AddCommand(validateCmd)
 }
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// baselineVersion is the oldest PowerShell the checks assume: Windows PowerShell 5.1, which
+// ships with every supported Windows. Anything it supports needs no newer version.
+const baselineVersion = "5.1"
+
+// versionFeature is syntax or a command that needs at least Version, shown in messages as
+// Syntax
+type versionFeature struct {
+	Version string
+	Syntax  string
+}
+
+// languageFeatures are the syntax parse.ps1 reports, by kind
+var languageFeatures = map[string]versionFeature{
+	"Ternary":                  {"7.0", "a ? b : c"},
+	"NullCoalescing":           {"7.0", "??"},
+	"NullCoalescingAssignment": {"7.0", "??="},
+	"PipelineChainAnd":         {"7.0", "&&"},
+	"PipelineChainOr":          {"7.0", "||"},
+	"NullConditionalMember":    {"7.1", "?."},
+	"NullConditionalIndex":     {"7.1", "?[]"},
+}
+
+// commandVersions are the cmdlets added after Windows PowerShell 5.1, by lowercase name
+var commandVersions = map[string]string{
+	"get-uptime":           "6.0",
+	"remove-alias":         "6.0",
+	"remove-service":       "6.0",
+	"convertfrom-markdown": "6.1",
+	"test-json":            "6.1",
+	"join-string":          "6.2",
+	"get-error":            "7.0",
+}
+
+// parameterVersions are the parameters added after Windows PowerShell 5.1 to cmdlets that
+// exist there, by lowercase cmdlet and parameter name
+var parameterVersions = map[string]map[string]string{
+	"foreach-object":    {"parallel": "7.0", "throttlelimit": "7.0", "asjob": "7.0"},
+	"convertfrom-json":  {"ashashtable": "6.0", "depth": "6.2", "noenumerate": "7.0"},
+	"get-content":       {"asbytestream": "6.0"},
+	"set-content":       {"asbytestream": "6.0"},
+	"add-content":       {"asbytestream": "6.0"},
+	"invoke-webrequest": {"skipcertificatecheck": "6.0", "form": "6.1", "resume": "6.1", "skiphttperrorcheck": "7.0"},
+	"invoke-restmethod": {"skipcertificatecheck": "6.0", "responseheadersvariable": "6.0", "form": "6.1", "resume": "6.1", "statuscodevariable": "7.0", "skiphttperrorcheck": "7.0"},
+	"select-string":     {"raw": "7.0", "noemphasis": "7.0"},
+}
+
+// minParameterPrefix is the shortest abbreviation of a parameter name that is matched, so
+// a short prefix shared with older parameters is not taken for a newer one
+const minParameterPrefix = 4
+
+// VersionUse is code that needs at least Version, located at the code
+type VersionUse struct {
+	Extent
+	Version string
+	Syntax  string
+}
+
+// MinimumVersion is the oldest PowerShell a file runs on, as far as its syntax and cmdlets
+// tell, and the code that needs it. Declared is the file's #Requires -Version, if any.
+type MinimumVersion struct {
+	Version  string `json:"version"`
+	Syntax   string `json:"syntax,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Declared string `json:"declared,omitempty"`
+}
+
+// versionUses returns the syntax and cmdlets of a file that need a newer PowerShell than
+// Windows PowerShell 5.1, in source order
+func versionUses(tree *SyntaxTree) []VersionUse {
+	var uses []VersionUse
+	for _, feature := range tree.Features {
+		if known, ok := languageFeatures[feature.Kind]; ok {
+			uses = append(uses, VersionUse{feature.Extent, known.Version, known.Syntax})
+		}
+	}
+	for _, command := range tree.Commands {
+		// Aliases such as % are named by the command they run
+		display := command.unqualifiedName()
+		if full, ok := commandAliases[strings.ToLower(display)]; ok {
+			display = full
+		}
+		name := strings.ToLower(display)
+		if version, ok := commandVersions[name]; ok {
+			uses = append(uses, VersionUse{command.Extent, version, display})
+		}
+		for _, parameter := range command.Parameters {
+			if full, version, ok := newerParameter(name, parameter); ok {
+				uses = append(uses, VersionUse{command.Extent, version, display + " -" + full})
+			}
+		}
+	}
+	sort.SliceStable(uses, func(i, j int) bool {
+		if uses[i].Line != uses[j].Line {
+			return uses[i].Line < uses[j].Line
+		}
+		return uses[i].Column < uses[j].Column
+	})
+	return uses
+}
+
+// newerParameter looks up a parameter of a cmdlet, possibly abbreviated, among those added
+// after Windows PowerShell 5.1 and returns its full name and version
+func newerParameter(command, parameter string) (string, string, bool) {
+	parameter = strings.ToLower(parameter)
+	var matches []string
+	for name := range parameterVersions[command] {
+		if parameter == name {
+			return parameterNames[name], parameterVersions[command][name], true
+		}
+		if len(parameter) >= minParameterPrefix && strings.HasPrefix(name, parameter) {
+			matches = append(matches, name)
+		}
+	}
+	// PowerShell rejects an ambiguous abbreviation, so it needs no version
+	if len(matches) != 1 {
+		return "", "", false
+	}
+	return parameterNames[matches[0]], parameterVersions[command][matches[0]], true
+}
+
+// parameterNames spells the parameters of parameterVersions as the cmdlets do
+var parameterNames = map[string]string{
+	"parallel":                "Parallel",
+	"throttlelimit":           "ThrottleLimit",
+	"asjob":                   "AsJob",
+	"ashashtable":             "AsHashtable",
+	"depth":                   "Depth",
+	"noenumerate":             "NoEnumerate",
+	"asbytestream":            "AsByteStream",
+	"skipcertificatecheck":    "SkipCertificateCheck",
+	"form":                    "Form",
+	"resume":                  "Resume",
+	"skiphttperrorcheck":      "SkipHttpErrorCheck",
+	"responseheadersvariable": "ResponseHeadersVariable",
+	"statuscodevariable":      "StatusCodeVariable",
+	"raw":                     "Raw",
+	"noemphasis":              "NoEmphasis",
+}
+
+// newestUse returns the first of the uses needing the newest version, and false when there
+// are none
+func newestUse(uses []VersionUse) (VersionUse, bool) {
+	var newest VersionUse
+	for _, use := range uses {
+		if newest.Version == "" || compareVersions(use.Version, newest.Version) > 0 {
+			newest = use
+		}
+	}
+	return newest, newest.Version != ""
+}
+
+// minimumVersion returns the oldest PowerShell a file needs: the version of its newest
+// syntax or cmdlet, or the baseline when it uses nothing newer
+func minimumVersion(tree *SyntaxTree) MinimumVersion {
+	minimum := MinimumVersion{Version: baselineVersion}
+	if tree.RequiredVersion != "" {
+		minimum.Declared = shortVersion(tree.RequiredVersion)
+	}
+	if use, ok := newestUse(versionUses(tree)); ok {
+		minimum.Version, minimum.Syntax = use.Version, use.Syntax
+		minimum.Line, minimum.Column = use.Line, use.Column
+	}
+	return minimum
+}
+
+// compareVersions compares dotted versions such as 7.0 and 5.1 part by part, with missing
+// parts counting as 0
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// shortVersion drops trailing .0 parts beyond the minor version, so #Requires -Version 7
+// reads as 7.0 and 7.2.0.0 as 7.2
+func shortVersion(version string) string {
+	parts := strings.Split(version, ".")
+	for len(parts) > 2 && parts[len(parts)-1] == "0" {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 1 {
+		parts = append(parts, "0")
+	}
+	return strings.Join(parts, ".")
+}
+
+// checkRequiredVersion reports code needing a newer PowerShell than the file's
+// #Requires -Version declares
+func checkRequiredVersion(tree *SyntaxTree, report func(Extent, string)) {
+	if tree.RequiredVersion == "" {
+		return
+	}
+	declared := shortVersion(tree.RequiredVersion)
+	for _, use := range versionUses(tree) {
+		if compareVersions(use.Version, declared) > 0 {
+			report(use.Extent, T("validate.requires_older_version", use.Syntax, use.Version, declared))
+		}
+	}
+}
+
+// checkMinimumVersion reports the code that makes a file need a newer PowerShell than
+// Windows PowerShell 5.1 when the file does not declare it with #Requires -Version
+func checkMinimumVersion(tree *SyntaxTree, report func(Extent, string)) {
+	if tree.RequiredVersion != "" {
+		return
+	}
+	if use, ok := newestUse(versionUses(tree)); ok {
+		report(use.Extent, T("validate.needs_newer_version", use.Syntax, use.Version))
+	}
+}
@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long --watch waits after the last change before validating, so an
+// editor saving a file in several steps or a checkout touching many files validates once
+const watchDebounce = 300 * time.Millisecond
+
+// clearScreen moves the cursor home and clears the terminal before a redraw
+const clearScreen = "\x1b[H\x1b[2J"
+
+// watchSession holds the latest result of every watched file between redraws.
+// ConfigProblem explains why a changed configuration file was not applied.
+type watchSession struct {
+	out           io.Writer
+	clear         bool
+	header        string
+	configProblem string
+	watcher       *fsnotify.Watcher
+	results       map[string]FileResult
+}
+
+// checkWatchFlags rejects the flags --watch cannot honour: it validates the working tree
+// as it changes with one PowerShell executable and prints a summary rather than a report
+func checkWatchFlags() error {
+	conflicts := []struct {
+		flag string
+		set  bool
+	}{
+		{"--staged", validateStaged},
+		{"--changed", validateChanged != ""},
+		{"--commits", validateCommits != ""},
+		{"--fix", validateFix},
+		{"--tests", validateTests},
+		{"--format", validateFormat != formatText},
+		{"--output", validateOutput != ""},
+		{"more than one --powershell", len(validatePowerShell) > 1},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return exitError(exitUsage, fmt.Errorf("--watch cannot be combined with %s", conflict.flag))
+		}
+	}
+	return nil
+}
+
+// watchValidation validates every file, then watches the directory and validates the files
+// affected by each change, redrawing the summary, until interrupted
+func watchValidation(out io.Writer) error {
+	if err := checkWatchFlags(); err != nil {
+		return err
+	}
+	if !isPowerShellAvailable() {
+		fmt.Fprintln(out, T("validate.title"))
+		fmt.Fprintln(out, T("validate.pwsh_missing"))
+		fmt.Fprintln(out, "   "+T("validate.pwsh_install"))
+		return reported(errPowerShellNotFound)
+	}
+
+	var header bytes.Buffer
+	fmt.Fprintln(&header, T("validate.pwsh_detected"))
+	if !detectScriptAnalyzer(&header) {
+		out.Write(header.Bytes())
+		return reported(exitError(exitUsage, fmt.Errorf("PSScriptAnalyzer settings not found: %s", validateSettings)))
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return exitError(exitEnvironment, fmt.Errorf("cannot watch for changes: %w", err))
+	}
+	defer watcher.Close()
+
+	session := &watchSession{
+		out:     out,
+		clear:   isTerminal(out),
+		header:  header.String(),
+		watcher: watcher,
+		results: map[string]FileResult{},
+	}
+	files, err := session.watch(".")
+	if err != nil {
+		return exitError(exitEnvironment, fmt.Errorf("cannot watch for changes: %w", err))
+	}
+	session.validate(files, nil)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	changed := map[string]bool{}
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, T("validate.watch_stopped"))
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			changed[filepath.Clean(event.Name)] = true
+			debounce.Reset(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return exitError(exitEnvironment, fmt.Errorf("cannot watch for changes: %w", err))
+		case <-debounce.C:
+			var paths []string
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			changed = map[string]bool{}
+			session.update(paths)
+		}
+	}
+}
+
+// watch starts watching the directories below root and returns the PowerShell files in them
+func (s *watchSession) watch(root string) ([]string, error) {
+	files, dirs := scanPowerShellTree(root)
+	for _, dir := range dirs {
+		if err := s.watcher.Add(dir); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// update validates the files affected by the changed paths and forgets deleted ones. New
+// directories are watched and their files validated, since they may have been written
+// before the watch started.
+func (s *watchSession) update(paths []string) {
+	for _, path := range paths {
+		if isDiscoverySetting(path) {
+			s.restart()
+			return
+		}
+	}
+
+	var more []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() && newFileDiscovery().pathSkipReason(path, true) == "" {
+			// A directory that cannot be watched is still validated now
+			files, _ := s.watch(path)
+			more = append(more, files...)
+		}
+	}
+
+	// Files inside a directory that was deleted or moved away get no events of their own
+	for _, file := range s.files() {
+		for _, path := range paths {
+			if strings.HasPrefix(file, path+string(filepath.Separator)) {
+				if _, err := os.Stat(path); err != nil {
+					more = append(more, file)
+				}
+			}
+		}
+	}
+
+	var files, removed []string
+	for _, file := range affectedFiles(append(paths, more...), s.files()) {
+		if _, err := os.Stat(file); err != nil {
+			delete(s.results, file)
+			removed = append(removed, file)
+		} else {
+			files = append(files, file)
+		}
+	}
+	if len(files) > 0 || len(removed) > 0 {
+		s.validate(files, removed)
+	}
+}
+
+// isDiscoverySetting reports whether a path is a configuration or ignore file, which can
+// change which files are validated and what is reported for them
+func isDiscoverySetting(path string) bool {
+	name := filepath.Base(path)
+	return containsString(projectConfigFiles, name) || name == ".gitignore" || name == ignoreFileName
+}
+
+// restart reloads the configuration and searches the directory again with the current
+// ignore files, then validates every file. Files no longer found are dropped.
+func (s *watchSession) restart() {
+	s.reloadConfig()
+	files, _ := s.watch(".")
+	var removed []string
+	for _, file := range s.files() {
+		if !containsString(files, file) {
+			removed = append(removed, file)
+		}
+	}
+	s.results = map[string]FileResult{}
+	s.validate(files, removed)
+}
+
+// reloadConfig reads the project configuration again. A configuration that is not valid is
+// not applied: the previous one stays in use until the file is fixed.
+func (s *watchSession) reloadConfig() {
+	previous := projectConfig
+	problems, err := loadProjectConfig()
+	s.configProblem = ""
+	switch {
+	case err != nil:
+		projectConfig, s.configProblem = previous, err.Error()
+	case len(problems) > 0:
+		path, _ := findProjectConfig()
+		projectConfig, s.configProblem = previous, fmt.Sprintf("%s, %s", path, problems[0])
+	}
+}
+
+// files returns the watched files that have been validated
+func (s *watchSession) files() []string {
+	files := make([]string, 0, len(s.results))
+	for file := range s.results {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// validate validates files and redraws the summary, naming what changed
+func (s *watchSession) validate(files, removed []string) {
+	for _, result := range validateFiles(io.Discard, files, validateJobs) {
+		s.results[result.File] = result
+	}
+	s.draw(files, removed)
+}
+
+// draw prints the compact summary: the files that pass and, for every other file, its
+// errors and warnings
+func (s *watchSession) draw(validated, removed []string) {
+	if s.clear {
+		fmt.Fprint(s.out, clearScreen)
+	} else {
+		fmt.Fprintln(s.out)
+	}
+	fmt.Fprintln(s.out, T("validate.watch_title", time.Now().Format("15:04:05")))
+	fmt.Fprintln(s.out, "=============================================")
+	fmt.Fprint(s.out, s.header)
+	if projectConfig.path != "" {
+		fmt.Fprintln(s.out, T("validate.using_config", projectConfig.path))
+	}
+	if s.configProblem != "" {
+		fmt.Fprintln(s.out, T("validate.watch_config_invalid", s.configProblem))
+	}
+	if len(validated) > 0 {
+		fmt.Fprintln(s.out, T("validate.watch_validated", len(validated), strings.Join(validated, ", ")))
+	}
+	if len(removed) > 0 {
+		fmt.Fprintln(s.out, T("validate.watch_removed", strings.Join(removed, ", ")))
+	}
+	fmt.Fprintln(s.out)
+
+	passed, failed := 0, 0
+	for _, file := range s.files() {
+		result := s.results[file]
+		problems := []Finding{}
+		for _, finding := range result.Findings {
+			if finding.Severity != severityInfo {
+				problems = append(problems, finding)
+			}
+		}
+		switch {
+		case !result.Valid:
+			failed++
+			fmt.Fprintln(s.out, "❌ "+file)
+		case len(problems) > 0:
+			passed++
+			fmt.Fprintln(s.out, "⚠️  "+file)
+		default:
+			passed++
+			continue
+		}
+		for _, check := range result.Checks {
+			if check.Problem != "" {
+				fmt.Fprintf(s.out, "     • %s %s: %s\n", severityIcons[severityError], check.Name, check.Problem)
+			}
+		}
+		for _, finding := range problems {
+			printFinding(s.out, finding)
+		}
+	}
+
+	fmt.Fprintln(s.out)
+	switch {
+	case len(s.results) == 0:
+		fmt.Fprintln(s.out, T("validate.no_files"))
+	case failed == 0:
+		fmt.Fprintln(s.out, T("validate.watch_passed", passed))
+	default:
+		fmt.Fprintln(s.out, T("validate.watch_failed", failed, passed))
+	}
+	fmt.Fprintln(s.out, T("validate.watch_waiting"))
+}
+
+// affectedFiles returns the files to validate again after paths changed: the changed
+// PowerShell files validate would find, and the known module manifests and script modules
+// in a directory above a changed file, since their exports and import depend on the
+// scripts beside them
+func affectedFiles(paths, known []string) []string {
+	var files []string
+	seen := map[string]bool{}
+	add := func(file string) {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	changed := selectChangedFiles(paths)
+	for _, file := range changed {
+		add(file)
+	}
+	for _, file := range changed {
+		for _, module := range known {
+			ext := strings.ToLower(filepath.Ext(module))
+			if module == file || (ext != ".psd1" && ext != ".psm1") {
+				continue
+			}
+			if dir := filepath.Dir(module); dir == "." || strings.HasPrefix(file, dir+string(filepath.Separator)) {
+				add(module)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// isTerminal reports whether out is an interactive terminal, where the summary is redrawn
+// in place
+func isTerminal(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}